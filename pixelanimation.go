@@ -0,0 +1,119 @@
+package pixelding
+
+import "time"
+
+const (
+	AnimationLoop = iota
+	AnimationOnce
+	AnimationPingPong
+)
+
+// Animation plays the segments of a PixelPicture as frames. Frames holds the segment numbers in
+// playing order, Durations the time of each frame, frames without a duration use Duration
+type Animation struct {
+	Picture   *PixelPicture
+	Frames    []int
+	Durations []time.Duration
+	Duration  time.Duration
+	Mode      int
+}
+
+// NewAnimation creates a looping animation of a picture, every frame is shown for d. Without
+// frames all segments of the picture are played in order
+// ----------------------------------------------------------------------------------------------------------------------
+func NewAnimation(picture *PixelPicture, d time.Duration, frames ...int) *Animation {
+	a := &Animation{Picture: picture, Duration: d, Mode: AnimationLoop}
+	if len(frames) == 0 {
+		for i := 1; i <= picture.Segments(); i++ {
+			frames = append(frames, i)
+		}
+	}
+	a.Frames = frames
+	return a
+}
+
+// Segments returns the number of segments of a picture
+// ----------------------------------------------------------------------------------------------------------------------
+func (pic *PixelPicture) Segments() int {
+	if pic.SegX <= 0 || pic.SegY <= 0 {
+		return 1
+	}
+	return (pic.SizeX / pic.SegX) * (pic.SizeY / pic.SegY)
+}
+
+// FrameDuration returns the duration of the frame at index i
+// ----------------------------------------------------------------------------------------------------------------------
+func (a *Animation) FrameDuration(i int) time.Duration {
+	if i >= 0 && i < len(a.Durations) && a.Durations[i] > 0 {
+		return a.Durations[i]
+	}
+	return a.Duration
+}
+
+// Length returns the duration of one animation cycle, for AnimationPingPong forwards and back
+// ----------------------------------------------------------------------------------------------------------------------
+func (a *Animation) Length() time.Duration {
+	var l time.Duration
+	for _, i := range a.sequence() {
+		l += a.FrameDuration(i)
+	}
+	return l
+}
+
+// Frame returns the segment number shown at time t after the animation start. AnimationOnce
+// stops at the last frame, 0 is returned for an animation without frames
+// ----------------------------------------------------------------------------------------------------------------------
+func (a *Animation) Frame(t time.Duration) int {
+	seq := a.sequence()
+	if len(seq) == 0 {
+		return 0
+	}
+	l := a.Length()
+	if t < 0 || l <= 0 {
+		return a.Frames[seq[0]]
+	}
+	if t >= l {
+		if a.Mode == AnimationOnce {
+			return a.Frames[seq[len(seq)-1]]
+		}
+		t %= l
+	}
+	for _, i := range seq {
+		d := a.FrameDuration(i)
+		if t < d {
+			return a.Frames[i]
+		}
+		t -= d
+	}
+	return a.Frames[seq[len(seq)-1]]
+}
+
+// Done returns true if an AnimationOnce animation has shown its last frame at time t
+// ----------------------------------------------------------------------------------------------------------------------
+func (a *Animation) Done(t time.Duration) bool {
+	return a.Mode == AnimationOnce && t >= a.Length()
+}
+
+// sequence internal, returns the frame indices of one cycle
+// ----------------------------------------------------------------------------------------------------------------------
+func (a *Animation) sequence() []int {
+	seq := make([]int, 0, 2*len(a.Frames))
+	for i := range a.Frames {
+		seq = append(seq, i)
+	}
+	if a.Mode == AnimationPingPong {
+		for i := len(a.Frames) - 2; i > 0; i-- {
+			seq = append(seq, i)
+		}
+	}
+	return seq
+}
+
+// Animation draws the frame of an animation shown at time t at the x,y coordinates
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Animation(a *Animation, x0, y0 int, t time.Duration) error {
+	if f := a.Frame(t); f > 0 {
+		return p.Picture(a.Picture, x0, y0, f)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package pixelding
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnimationFrame(t *testing.T) {
+	ms := time.Millisecond
+	type step struct {
+		t     time.Duration
+		frame int
+		done  bool
+	}
+	tests := []struct {
+		name   string
+		anim   Animation
+		length time.Duration
+		steps  []step
+	}{
+		{"loop", Animation{Frames: []int{1, 2, 3}, Duration: 100 * ms, Mode: AnimationLoop}, 300 * ms,
+			[]step{{-1 * ms, 1, false}, {0, 1, false}, {99 * ms, 1, false}, {100 * ms, 2, false}, {250 * ms, 3, false},
+				{300 * ms, 1, false}, {1150 * ms, 3, false}}},
+		{"once", Animation{Frames: []int{1, 2, 3}, Duration: 100 * ms, Mode: AnimationOnce}, 300 * ms,
+			[]step{{0, 1, false}, {299 * ms, 3, false}, {300 * ms, 3, true}, {time.Hour, 3, true}}},
+		{"ping-pong", Animation{Frames: []int{1, 2, 3}, Duration: 100 * ms, Mode: AnimationPingPong}, 400 * ms,
+			[]step{{0, 1, false}, {150 * ms, 2, false}, {250 * ms, 3, false}, {350 * ms, 2, false}, {400 * ms, 1, false},
+				{650 * ms, 3, false}}},
+		{"durations", Animation{Frames: []int{5, 6, 7}, Durations: []time.Duration{50 * ms, 0, 200 * ms},
+			Duration: 100 * ms}, 350 * ms,
+			[]step{{49 * ms, 5, false}, {50 * ms, 6, false}, {149 * ms, 6, false}, {150 * ms, 7, false},
+				{349 * ms, 7, false}, {350 * ms, 5, false}}},
+		{"durations ping-pong", Animation{Frames: []int{1, 2, 3}, Durations: []time.Duration{10 * ms, 20 * ms, 30 * ms},
+			Mode: AnimationPingPong}, 80 * ms,
+			[]step{{9 * ms, 1, false}, {10 * ms, 2, false}, {30 * ms, 3, false}, {60 * ms, 2, false}, {80 * ms, 1, false}}},
+		{"single frame ping-pong", Animation{Frames: []int{4}, Duration: 100 * ms, Mode: AnimationPingPong}, 100 * ms,
+			[]step{{0, 4, false}, {99 * ms, 4, false}, {100 * ms, 4, false}, {time.Hour, 4, false}}},
+		{"two frame ping-pong", Animation{Frames: []int{1, 2}, Duration: 100 * ms, Mode: AnimationPingPong}, 200 * ms,
+			[]step{{0, 1, false}, {100 * ms, 2, false}, {200 * ms, 1, false}}},
+		{"no frames", Animation{Duration: 100 * ms, Mode: AnimationOnce}, 0,
+			[]step{{0, 0, true}, {time.Second, 0, true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if l := tt.anim.Length(); l != tt.length {
+				t.Errorf("Length() = %v, want %v", l, tt.length)
+			}
+			for _, s := range tt.steps {
+				if f := tt.anim.Frame(s.t); f != s.frame {
+					t.Errorf("Frame(%v) = %d, want %d", s.t, f, s.frame)
+				}
+				if d := tt.anim.Done(s.t); d != s.done {
+					t.Errorf("Done(%v) = %v, want %v", s.t, d, s.done)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAnimationSegments(t *testing.T) {
+	pic := &PixelPicture{SizeX: 6, SizeY: 4, SegX: 2, SegY: 2}
+	a := NewAnimation(pic, time.Second)
+	if len(a.Frames) != 6 || a.Frames[0] != 1 || a.Frames[5] != 6 {
+		t.Errorf("frames %v, want 1 to 6", a.Frames)
+	}
+	if a.Mode != AnimationLoop || a.Length() != 6*time.Second {
+		t.Errorf("mode %d length %v", a.Mode, a.Length())
+	}
+}
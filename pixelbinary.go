@@ -0,0 +1,323 @@
+package pixelding
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+const (
+	FormatJSON = iota
+	FormatBinary
+	FormatCompressed
+)
+
+const FormatError = "format error"
+
+// binary file layout: magic "PXD" + kind, version, flags, payload (zlib compressed with binCompressed)
+const (
+	binMagic      = "PXD"
+	binVersion    = 1
+	binCompressed = 0x01
+	binPalette    = 0x02
+	binFont       = 'F'
+	binStamp      = 'S'
+	binPicture    = 'P'
+)
+
+// binReader internal, reads little endian values and keeps the first error
+type binReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+// FileFormat set the format used by SaveFont, SaveStamp and SavePicture
+// need to be one of : FormatJSON (default), FormatBinary, FormatCompressed (binary, zlib compressed).
+// Loading detects the format by itself
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) FileFormat(format int) error {
+	switch format {
+	case FormatJSON, FormatBinary, FormatCompressed:
+		p.fileformat = format
+		return nil
+	default:
+		p.LastError = errors.New(FormatError)
+		return p.LastError
+	}
+}
+
+// marshal internal, encodes a font, stamp or picture in the current file format
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) marshal(v interface{}) ([]byte, error) {
+	if p.fileformat == FormatJSON {
+		return json.Marshal(v)
+	}
+	var kind byte
+	var flags byte
+	var payload bytes.Buffer
+	switch x := v.(type) {
+	case *PixelFont:
+		kind = binFont
+		encodeFont(&payload, x)
+	case *PixelStamp:
+		kind = binStamp
+		encodeStamp(&payload, x)
+	case *PixelPicture:
+		kind = binPicture
+		if encodePicture(&payload, x) {
+			flags |= binPalette
+		}
+	default:
+		return nil, errors.New(FormatError)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(binMagic)
+	buf.WriteByte(kind)
+	buf.WriteByte(binVersion)
+	if p.fileformat == FormatCompressed {
+		flags |= binCompressed
+		buf.WriteByte(flags)
+		z := zlib.NewWriter(&buf)
+		if _, err := z.Write(payload.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := z.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	buf.WriteByte(flags)
+	buf.Write(payload.Bytes())
+	return buf.Bytes(), nil
+}
+
+// unmarshal internal, decodes a JSON or binary font, stamp or picture. Truncated or corrupt binary data
+// returns a FormatError
+// ----------------------------------------------------------------------------------------------------------------------
+func unmarshal(buf []byte, v interface{}) error {
+	if len(buf) < 6 || string(buf[:3]) != binMagic {
+		return json.Unmarshal(buf, v)
+	}
+	kind, version, flags := buf[3], buf[4], buf[5]
+	if version != binVersion {
+		return errors.New(FormatError)
+	}
+	payload := buf[6:]
+	if flags&binCompressed != 0 {
+		z, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return errors.New(FormatError)
+		}
+		payload, err = io.ReadAll(z)
+		if err != nil {
+			return errors.New(FormatError)
+		}
+	}
+	b := &binReader{r: bytes.NewReader(payload)}
+	switch x := v.(type) {
+	case *PixelFont:
+		if kind != binFont {
+			return errors.New(FormatError)
+		}
+		decodeFont(b, x)
+	case *PixelStamp:
+		if kind != binStamp {
+			return errors.New(FormatError)
+		}
+		decodeStamp(b, x)
+	case *PixelPicture:
+		if kind != binPicture {
+			return errors.New(FormatError)
+		}
+		decodePicture(b, x, flags&binPalette != 0)
+	default:
+		return errors.New(FormatError)
+	}
+	if b.err != nil {
+		return errors.New(FormatError)
+	}
+	return nil
+}
+
+// put internal, writes a little endian value
+// ----------------------------------------------------------------------------------------------------------------------
+func put(w io.Writer, v interface{}) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+// read internal, reads a little endian value
+// ----------------------------------------------------------------------------------------------------------------------
+func (b *binReader) read(v interface{}) {
+	if b.err == nil {
+		b.err = binary.Read(b.r, binary.LittleEndian, v)
+	}
+}
+
+// count internal, reads a slice length and checks it against the remaining data
+// ----------------------------------------------------------------------------------------------------------------------
+func (b *binReader) count(size int) int {
+	var n uint32
+	b.read(&n)
+	if b.err == nil && int64(n)*int64(size) > int64(b.r.Len()) {
+		b.err = errors.New(FormatError)
+	}
+	if b.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+// int internal, reads an int32 value
+// ----------------------------------------------------------------------------------------------------------------------
+func (b *binReader) int() int {
+	var v int32
+	b.read(&v)
+	return int(v)
+}
+
+// encodeFont internal
+// ----------------------------------------------------------------------------------------------------------------------
+func encodeFont(w io.Writer, font *PixelFont) {
+	put(w, font.Prepared)
+	put(w, []int32{int32(font.Ascent), int32(font.Descent)})
+	codes := make([]int, 0, len(font.Chars))
+	for c := range font.Chars {
+		codes = append(codes, c)
+	}
+	sort.Ints(codes)
+	put(w, uint32(len(codes)))
+	for _, c := range codes {
+		ch := font.Chars[c]
+		put(w, []int32{int32(c), int32(ch.OffsetX), int32(ch.OffsetY), int32(ch.SizeX), int32(ch.SizeY),
+			int32(ch.Len), int32(ch.GN), int32(ch.GA), int32(ch.Advance)})
+		put(w, uint32(len(ch.Data)))
+		put(w, ch.Data)
+	}
+	var pairs [][3]int32
+	for l, m := range font.Kerning {
+		for r, k := range m {
+			pairs = append(pairs, [3]int32{int32(l), int32(r), int32(k)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0] || (pairs[i][0] == pairs[j][0] && pairs[i][1] < pairs[j][1])
+	})
+	put(w, uint32(len(pairs)))
+	put(w, pairs)
+}
+
+// decodeFont internal
+// ----------------------------------------------------------------------------------------------------------------------
+func decodeFont(b *binReader, font *PixelFont) {
+	b.read(&font.Prepared)
+	font.Ascent, font.Descent = b.int(), b.int()
+	n := b.count(36)
+	font.Chars = make(map[int]PixelChar, n)
+	for i := 0; i < n && b.err == nil; i++ {
+		c := b.int()
+		ch := PixelChar{}
+		ch.OffsetX, ch.OffsetY, ch.SizeX, ch.SizeY = b.int(), b.int(), b.int(), b.int()
+		ch.Len, ch.GN, ch.GA, ch.Advance = b.int(), b.int(), b.int(), b.int()
+		ch.Data = make([]uint64, b.count(8))
+		b.read(ch.Data)
+		font.Chars[c] = ch
+	}
+	pairs := make([][3]int32, b.count(12))
+	b.read(pairs)
+	for _, k := range pairs {
+		font.AddKerning(int(k[0]), int(k[1]), int(k[2]))
+	}
+}
+
+// encodeStamp internal
+// ----------------------------------------------------------------------------------------------------------------------
+func encodeStamp(w io.Writer, stamp *PixelStamp) {
+	put(w, stamp.Prepared)
+	put(w, int32(stamp.Len))
+	put(w, uint32(len(stamp.Data)))
+	put(w, stamp.Data)
+}
+
+// decodeStamp internal
+// ----------------------------------------------------------------------------------------------------------------------
+func decodeStamp(b *binReader, stamp *PixelStamp) {
+	b.read(&stamp.Prepared)
+	stamp.Len = b.int()
+	stamp.Data = make([]uint64, b.count(8))
+	b.read(stamp.Data)
+}
+
+// encodePicture internal, pictures with up to 256 different values are stored palette indexed,
+// returns true for palette indexed data
+// ----------------------------------------------------------------------------------------------------------------------
+func encodePicture(w io.Writer, pic *PixelPicture) bool {
+	var flags uint8
+	if pic.UseColorKey {
+		flags |= 1
+	}
+	if pic.Alpha {
+		flags |= 2
+	}
+	put(w, []int32{int32(pic.Mode), int32(pic.SizeX), int32(pic.SizeY), int32(pic.SegX), int32(pic.SegY)})
+	put(w, pic.ColorKey)
+	put(w, flags)
+
+	index := map[uint32]int{}
+	var palette []uint32
+	for _, c := range pic.Data {
+		if _, ok := index[c]; !ok {
+			if len(palette) == 256 {
+				palette = nil
+				break
+			}
+			index[c] = len(palette)
+			palette = append(palette, c)
+		}
+	}
+	if palette == nil {
+		put(w, uint32(len(pic.Data)))
+		put(w, pic.Data)
+		return false
+	}
+	put(w, uint32(len(palette)))
+	put(w, palette)
+	data := make([]byte, len(pic.Data))
+	for i, c := range pic.Data {
+		data[i] = byte(index[c])
+	}
+	put(w, uint32(len(data)))
+	put(w, data)
+	return true
+}
+
+// decodePicture internal
+// ----------------------------------------------------------------------------------------------------------------------
+func decodePicture(b *binReader, pic *PixelPicture, indexed bool) {
+	var flags uint8
+	pic.Mode, pic.SizeX, pic.SizeY, pic.SegX, pic.SegY = b.int(), b.int(), b.int(), b.int(), b.int()
+	b.read(&pic.ColorKey)
+	b.read(&flags)
+	pic.UseColorKey = flags&1 != 0
+	pic.Alpha = flags&2 != 0
+	if !indexed {
+		pic.Data = make([]uint32, b.count(4))
+		b.read(pic.Data)
+		return
+	}
+	palette := make([]uint32, b.count(4))
+	b.read(palette)
+	data := make([]byte, b.count(1))
+	b.read(data)
+	pic.Data = make([]uint32, len(data))
+	for i, c := range data {
+		if int(c) >= len(palette) {
+			b.err = errors.New(FormatError)
+			return
+		}
+		pic.Data[i] = palette[c]
+	}
+}
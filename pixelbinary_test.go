@@ -0,0 +1,150 @@
+package pixelding
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// testFont internal test helper, a font using all binary font fields
+func testFont() *PixelFont {
+	p := New(1, 1)
+	f := p.LoadStdFont()
+	f.Ascent, f.Descent = 5, 1
+	ch := f.Chars['A']
+	ch.OffsetX, ch.Advance = -1, 7
+	f.Chars['A'] = ch
+	f.AddKerning('A', 'V', -2)
+	f.AddKerning('L', 'T', 1)
+	return f
+}
+
+// testPicture internal test helper, a true color picture with n different colors
+func testPicture(n int) *PixelPicture {
+	pic := &PixelPicture{Mode: ModeTrueColor, ColorKey: 0xff00ff, UseColorKey: true, SizeX: n, SizeY: 2, SegX: 1, SegY: 1}
+	for i := 0; i < 2*n; i++ {
+		pic.Data = append(pic.Data, uint32(i%n*0x010203))
+	}
+	return pic
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	stamp := &PixelStamp{Prepared: true, Len: 5, Data: []uint64{1 << 63, 3 << 62, 0, 7 << 61}}
+	alpha := testPicture(10)
+	alpha.Alpha = true
+	alpha.Data[3] = 0x80ff0000
+	values := []struct {
+		name string
+		v    interface{}
+	}{
+		{"font", testFont()},
+		{"stamp", stamp},
+		{"palette picture", testPicture(256)},
+		{"true color picture", testPicture(257)},
+		{"alpha picture", alpha},
+	}
+	for _, format := range []int{FormatJSON, FormatBinary, FormatCompressed} {
+		p := New(1, 1)
+		if err := p.FileFormat(format); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range values {
+			buf, err := p.marshal(tt.v)
+			if err != nil {
+				t.Fatalf("format %d %s: %v", format, tt.name, err)
+			}
+			if binary := bytes.HasPrefix(buf, []byte(binMagic)); binary != (format != FormatJSON) {
+				t.Errorf("format %d %s: binary header %v", format, tt.name, binary)
+			}
+			got := reflect.New(reflect.TypeOf(tt.v).Elem())
+			if err := unmarshal(buf, got.Interface()); err != nil {
+				t.Fatalf("format %d %s: %v", format, tt.name, err)
+			}
+			if !reflect.DeepEqual(got.Interface(), tt.v) {
+				t.Errorf("format %d %s: round trip differs", format, tt.name)
+			}
+		}
+	}
+}
+
+func TestBinaryPalette(t *testing.T) {
+	p := New(1, 1)
+	_ = p.FileFormat(FormatBinary)
+	for _, tt := range []struct {
+		colors  int
+		palette bool
+	}{{1, true}, {256, true}, {257, false}, {1000, false}} {
+		buf, err := p.marshal(testPicture(tt.colors))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := buf[5]&binPalette != 0; got != tt.palette {
+			t.Errorf("%d colors: palette %v, want %v", tt.colors, got, tt.palette)
+		}
+	}
+}
+
+func TestBinaryVersion(t *testing.T) {
+	p := New(1, 1)
+	_ = p.FileFormat(FormatBinary)
+	buf, _ := p.marshal(testFont())
+	if buf[4] != 1 {
+		t.Errorf("version %d, want 1", buf[4])
+	}
+	for _, version := range []byte{0, 2} {
+		buf[4] = version
+		if err := unmarshal(buf, &PixelFont{}); err == nil || err.Error() != FormatError {
+			t.Errorf("version %d: error %v, want %s", version, err, FormatError)
+		}
+	}
+}
+
+func TestBinaryCorrupt(t *testing.T) {
+	for _, format := range []int{FormatBinary, FormatCompressed} {
+		p := New(1, 1)
+		_ = p.FileFormat(format)
+		for _, v := range []interface{}{testFont(), &PixelStamp{Len: 3, Data: []uint64{5}}, testPicture(3), testPicture(300)} {
+			buf, err := p.marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decode := func(b []byte) error {
+				return unmarshal(b, reflect.New(reflect.TypeOf(v).Elem()).Interface())
+			}
+			//every truncation is an error
+			for n := 6; n < len(buf); n++ {
+				if err := decode(buf[:n]); err == nil || err.Error() != FormatError {
+					t.Fatalf("format %d %T truncated to %d bytes: error %v", format, v, n, err)
+				}
+			}
+			//flipped bytes may decode to other values, but must not panic
+			for i := 6; i < len(buf); i++ {
+				b := append([]byte(nil), buf...)
+				b[i] ^= 0xff
+				_ = decode(b)
+			}
+			//a wrong kind is an error
+			b := append([]byte(nil), buf...)
+			b[3] = 'X'
+			if err := decode(b); err == nil || err.Error() != FormatError {
+				t.Errorf("format %d %T wrong kind: error %v", format, v, err)
+			}
+		}
+	}
+
+	//a palette index beyond the palette
+	var bad bytes.Buffer
+	bad.WriteString(binMagic + "P")
+	bad.WriteByte(binVersion)
+	bad.WriteByte(binPalette)
+	put(&bad, []int32{ModeTrueColor, 2, 1, 0, 0})
+	put(&bad, uint32(0))
+	put(&bad, uint8(0))
+	put(&bad, uint32(1))
+	put(&bad, []uint32{0xffffff})
+	put(&bad, uint32(2))
+	put(&bad, []byte{0, 1})
+	if err := unmarshal(bad.Bytes(), &PixelPicture{}); err == nil || err.Error() != FormatError {
+		t.Errorf("palette index out of range: error %v", err)
+	}
+}
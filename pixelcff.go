@@ -0,0 +1,576 @@
+package pixelding
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// cffSubrDepth the maximum nesting of charstring subroutine calls
+const cffSubrDepth = 10
+
+// cffFont internal, the charstrings and subroutines of a CFF table (OpenType fonts with PostScript outlines)
+type cffFont struct {
+	charStrings [][]byte
+	gsubrs      [][]byte
+	subrs       [][][]byte // local subroutines of every font dict, one dict for non CID fonts
+	fdSelect    []int      // font dict of every glyph, nil for non CID fonts
+}
+
+// cffCharString internal, the state of one charstring run
+type cffCharString struct {
+	cff      *cffFont
+	subrs    [][]byte
+	scale    float64
+	stack    []float64
+	x, y     float64
+	nStems   int
+	width    bool // the optional width operand is consumed
+	contour  [][2]float64
+	contours [][][2]float64
+}
+
+// parseCFF internal, reads the CFF table: header, name, top dict, string and global subroutine INDEX, the
+// charstrings and the private dicts with their local subroutines
+// ----------------------------------------------------------------------------------------------------------------------
+func parseCFF(buf []byte, numGlyphs int) (*cffFont, error) {
+	if len(buf) < 4 || buf[0] != 1 {
+		return nil, errors.New(FontFormatError)
+	}
+	_, off, err := cffIndex(buf, int(buf[2])) //names
+	if err != nil {
+		return nil, err
+	}
+	top, off, err := cffIndex(buf, off)
+	if err != nil || len(top) == 0 {
+		return nil, errors.New(FontFormatError)
+	}
+	_, off, err = cffIndex(buf, off) //strings
+	if err != nil {
+		return nil, err
+	}
+	c := &cffFont{}
+	if c.gsubrs, _, err = cffIndex(buf, off); err != nil {
+		return nil, err
+	}
+	dict, err := cffDict(top[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(dict[17]) != 1 {
+		return nil, errors.New(FontFormatError)
+	}
+	if c.charStrings, _, err = cffIndex(buf, int(dict[17][0])); err != nil {
+		return nil, err
+	}
+	if len(c.charStrings) < numGlyphs {
+		return nil, errors.New(FontFormatError)
+	}
+
+	fdArray, fdSelect := dict[1236], dict[1237]
+	if len(fdArray) != 1 || len(fdSelect) != 1 {
+		//non CID font, one private dict
+		subrs, err := cffPrivate(buf, dict[18])
+		if err != nil {
+			return nil, err
+		}
+		c.subrs = [][][]byte{subrs}
+		return c, nil
+	}
+	fds, _, err := cffIndex(buf, int(fdArray[0]))
+	if err != nil {
+		return nil, err
+	}
+	for _, fd := range fds {
+		d, err := cffDict(fd)
+		if err != nil {
+			return nil, err
+		}
+		subrs, err := cffPrivate(buf, d[18])
+		if err != nil {
+			return nil, err
+		}
+		c.subrs = append(c.subrs, subrs)
+	}
+	if c.fdSelect, err = cffFDSelect(buf, int(fdSelect[0]), numGlyphs, len(fds)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// cffIndex internal, reads the INDEX at off and returns its entries and the offset behind it
+// ----------------------------------------------------------------------------------------------------------------------
+func cffIndex(buf []byte, off int) ([][]byte, int, error) {
+	if off < 0 || off+2 > len(buf) {
+		return nil, 0, errors.New(FontFormatError)
+	}
+	count := int(binary.BigEndian.Uint16(buf[off:]))
+	if count == 0 {
+		return nil, off + 2, nil
+	}
+	if off+3 > len(buf) {
+		return nil, 0, errors.New(FontFormatError)
+	}
+	size := int(buf[off+2])
+	if size < 1 || size > 4 || off+3+(count+1)*size > len(buf) {
+		return nil, 0, errors.New(FontFormatError)
+	}
+	offset := func(i int) int {
+		v := 0
+		for _, b := range buf[off+3+i*size : off+3+(i+1)*size] {
+			v = v<<8 | int(b)
+		}
+		return v
+	}
+	//offsets count from the byte before the data
+	data := off + 3 + (count+1)*size - 1
+	items := make([][]byte, count)
+	for i := range items {
+		a, b := offset(i), offset(i+1)
+		if a < 1 || a > b || data+b > len(buf) {
+			return nil, 0, errors.New(FontFormatError)
+		}
+		items[i] = buf[data+a : data+b]
+	}
+	return items, data + offset(count), nil
+}
+
+// cffDict internal, reads a DICT into its operands by operator, two byte operators are 1200 + second byte
+// ----------------------------------------------------------------------------------------------------------------------
+func cffDict(d []byte) (map[int][]float64, error) {
+	dict := map[int][]float64{}
+	var ops []float64
+	for i := 0; i < len(d); {
+		b := int(d[i])
+		switch {
+		case b <= 21:
+			op := b
+			if b == 12 {
+				if i+1 >= len(d) {
+					return nil, errors.New(FontFormatError)
+				}
+				op = 1200 + int(d[i+1])
+				i++
+			}
+			dict[op], ops = ops, nil
+			i++
+		case b == 30:
+			v, n, err := cffReal(d[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, v)
+			i += 1 + n
+		case b == 29:
+			if i+5 > len(d) {
+				return nil, errors.New(FontFormatError)
+			}
+			ops = append(ops, float64(int32(binary.BigEndian.Uint32(d[i+1:]))))
+			i += 5
+		default:
+			v, n := cffNumber(d[i:])
+			if n == 0 {
+				return nil, errors.New(FontFormatError)
+			}
+			ops = append(ops, v)
+			i += n
+		}
+	}
+	return dict, nil
+}
+
+// cffNumber internal, reads an integer operand shared by DICT and charstrings (28, 32..254), returns the
+// value and the bytes used, 0 for anything else
+// ----------------------------------------------------------------------------------------------------------------------
+func cffNumber(d []byte) (float64, int) {
+	b := int(d[0])
+	switch {
+	case b == 28 && len(d) >= 3:
+		return float64(int16(binary.BigEndian.Uint16(d[1:]))), 3
+	case b >= 32 && b <= 246:
+		return float64(b - 139), 1
+	case b >= 247 && b <= 250 && len(d) >= 2:
+		return float64((b-247)*256 + int(d[1]) + 108), 2
+	case b >= 251 && b <= 254 && len(d) >= 2:
+		return float64(-(b-251)*256 - int(d[1]) - 108), 2
+	}
+	return 0, 0
+}
+
+// cffReal internal, reads the nibbles of a real DICT operand, returns the value and the bytes used
+// ----------------------------------------------------------------------------------------------------------------------
+func cffReal(d []byte) (float64, int, error) {
+	s := ""
+	for i, b := range d {
+		for _, nib := range []byte{b >> 4, b & 15} {
+			switch {
+			case nib <= 9:
+				s += string(rune('0' + nib))
+			case nib == 0xa:
+				s += "."
+			case nib == 0xb:
+				s += "E"
+			case nib == 0xc:
+				s += "E-"
+			case nib == 0xe:
+				s += "-"
+			case nib == 0xf:
+				v, err := strconv.ParseFloat(s, 64)
+				if err != nil && s != "" {
+					return 0, 0, errors.New(FontFormatError)
+				}
+				return v, i + 1, nil
+			default:
+				return 0, 0, errors.New(FontFormatError)
+			}
+		}
+	}
+	return 0, 0, errors.New(FontFormatError)
+}
+
+// cffPrivate internal, reads the local subroutines of the private dict at size, offset. Their offset is
+// relative to the private dict
+// ----------------------------------------------------------------------------------------------------------------------
+func cffPrivate(buf []byte, private []float64) ([][]byte, error) {
+	if len(private) != 2 {
+		return nil, nil
+	}
+	size, off := int(private[0]), int(private[1])
+	if size < 0 || off < 0 || off+size > len(buf) {
+		return nil, errors.New(FontFormatError)
+	}
+	dict, err := cffDict(buf[off : off+size])
+	if err != nil {
+		return nil, err
+	}
+	if len(dict[19]) != 1 {
+		return nil, nil
+	}
+	subrs, _, err := cffIndex(buf, off+int(dict[19][0]))
+	return subrs, err
+}
+
+// cffFDSelect internal, reads the font dict of every glyph of a CID font (format 0 and 3)
+// ----------------------------------------------------------------------------------------------------------------------
+func cffFDSelect(buf []byte, off, numGlyphs, numFDs int) ([]int, error) {
+	if off < 0 || off >= len(buf) {
+		return nil, errors.New(FontFormatError)
+	}
+	sel := make([]int, numGlyphs)
+	switch buf[off] {
+	case 0:
+		if off+1+numGlyphs > len(buf) {
+			return nil, errors.New(FontFormatError)
+		}
+		for g := range sel {
+			sel[g] = int(buf[off+1+g])
+		}
+	case 3:
+		if off+3 > len(buf) {
+			return nil, errors.New(FontFormatError)
+		}
+		n := int(binary.BigEndian.Uint16(buf[off+1:]))
+		if off+3+n*3+2 > len(buf) {
+			return nil, errors.New(FontFormatError)
+		}
+		for i := 0; i < n; i++ {
+			r := off + 3 + i*3
+			first, last := int(binary.BigEndian.Uint16(buf[r:])), int(binary.BigEndian.Uint16(buf[r+3:]))
+			for g := first; g < last && g < numGlyphs; g++ {
+				sel[g] = int(buf[r+2])
+			}
+		}
+	default:
+		return nil, errors.New(FontFormatError)
+	}
+	for _, fd := range sel {
+		if fd >= numFDs {
+			return nil, errors.New(FontFormatError)
+		}
+	}
+	return sel, nil
+}
+
+// outline internal, runs the Type 2 charstring of a glyph and returns its contours as polygons in pixel
+// coordinates, y down from the baseline. A broken charstring returns no contours
+// ----------------------------------------------------------------------------------------------------------------------
+func (c *cffFont) outline(g int, scale float64) [][][2]float64 {
+	if g < 0 || g >= len(c.charStrings) {
+		return nil
+	}
+	cs := cffCharString{cff: c, scale: scale}
+	fd := 0
+	if c.fdSelect != nil {
+		fd = c.fdSelect[g]
+	}
+	if fd < len(c.subrs) {
+		cs.subrs = c.subrs[fd]
+	}
+	if _, err := cs.run(c.charStrings[g], 0); err != nil {
+		return nil
+	}
+	cs.closeContour()
+	return cs.contours
+}
+
+// cffBias internal, the bias added to subroutine numbers
+// ----------------------------------------------------------------------------------------------------------------------
+func cffBias(subrs [][]byte) int {
+	switch {
+	case len(subrs) < 1240:
+		return 107
+	case len(subrs) < 33900:
+		return 1131
+	}
+	return 32768
+}
+
+// run internal, interprets a charstring or subroutine, returns true at endchar
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) run(code []byte, depth int) (bool, error) {
+	if depth > cffSubrDepth {
+		return false, errors.New(FontFormatError)
+	}
+	for i := 0; i < len(code); {
+		b := int(code[i])
+		if b == 255 {
+			if i+5 > len(code) {
+				return false, errors.New(FontFormatError)
+			}
+			cs.stack = append(cs.stack, float64(int32(binary.BigEndian.Uint32(code[i+1:])))/65536)
+			i += 5
+			continue
+		}
+		if b == 28 || b >= 32 {
+			v, n := cffNumber(code[i:])
+			if n == 0 {
+				return false, errors.New(FontFormatError)
+			}
+			cs.stack = append(cs.stack, v)
+			i += n
+			continue
+		}
+		i++
+		s := cs.stack
+		switch b {
+		case 1, 3, 18, 23: //hstem, vstem, hstemhm, vstemhm
+			cs.stems()
+		case 19, 20: //hintmask, cntrmask, the operands are an implicit vstem
+			cs.stems()
+			i += (cs.nStems + 7) / 8
+		case 21: //rmoveto
+			s = cs.dropWidth(2)
+			if len(s) < 2 {
+				return false, errors.New(FontFormatError)
+			}
+			cs.moveTo(s[0], s[1])
+		case 22: //hmoveto
+			s = cs.dropWidth(1)
+			if len(s) < 1 {
+				return false, errors.New(FontFormatError)
+			}
+			cs.moveTo(s[0], 0)
+		case 4: //vmoveto
+			s = cs.dropWidth(1)
+			if len(s) < 1 {
+				return false, errors.New(FontFormatError)
+			}
+			cs.moveTo(0, s[0])
+		case 5: //rlineto
+			for k := 0; k+1 < len(s); k += 2 {
+				cs.lineTo(s[k], s[k+1])
+			}
+		case 6, 7: //hlineto, vlineto, alternating
+			horizontal := b == 6
+			for _, d := range s {
+				if horizontal {
+					cs.lineTo(d, 0)
+				} else {
+					cs.lineTo(0, d)
+				}
+				horizontal = !horizontal
+			}
+		case 8: //rrcurveto
+			for k := 0; k+5 < len(s); k += 6 {
+				cs.curveTo(s[k], s[k+1], s[k+2], s[k+3], s[k+4], s[k+5])
+			}
+		case 24: //rcurveline
+			k := 0
+			for ; k+7 < len(s); k += 6 {
+				cs.curveTo(s[k], s[k+1], s[k+2], s[k+3], s[k+4], s[k+5])
+			}
+			if k+1 < len(s) {
+				cs.lineTo(s[k], s[k+1])
+			}
+		case 25: //rlinecurve
+			k := 0
+			for ; k+7 < len(s); k += 2 {
+				cs.lineTo(s[k], s[k+1])
+			}
+			if k+5 < len(s) {
+				cs.curveTo(s[k], s[k+1], s[k+2], s[k+3], s[k+4], s[k+5])
+			}
+		case 26: //vvcurveto
+			dx, k := 0.0, 0
+			if len(s)%2 == 1 {
+				dx, k = s[0], 1
+			}
+			for ; k+3 < len(s); k += 4 {
+				cs.curveTo(dx, s[k], s[k+1], s[k+2], 0, s[k+3])
+				dx = 0
+			}
+		case 27: //hhcurveto
+			dy, k := 0.0, 0
+			if len(s)%2 == 1 {
+				dy, k = s[0], 1
+			}
+			for ; k+3 < len(s); k += 4 {
+				cs.curveTo(s[k], dy, s[k+1], s[k+2], s[k+3], 0)
+				dy = 0
+			}
+		case 30, 31: //vhcurveto, hvcurveto, alternating, the last curve may end with an extra operand
+			horizontal := b == 31
+			for k := 0; k+3 < len(s); k += 4 {
+				last := 0.0
+				if len(s)-k == 5 {
+					last = s[k+4]
+				}
+				if horizontal {
+					cs.curveTo(s[k], 0, s[k+1], s[k+2], last, s[k+3])
+				} else {
+					cs.curveTo(0, s[k], s[k+1], s[k+2], s[k+3], last)
+				}
+				horizontal = !horizontal
+			}
+		case 10, 29: //callsubr, callgsubr
+			subrs := cs.subrs
+			if b == 29 {
+				subrs = cs.cff.gsubrs
+			}
+			if len(s) == 0 {
+				return false, errors.New(FontFormatError)
+			}
+			n := int(s[len(s)-1]) + cffBias(subrs)
+			if n < 0 || n >= len(subrs) {
+				return false, errors.New(FontFormatError)
+			}
+			cs.stack = s[:len(s)-1]
+			end, err := cs.run(subrs[n], depth+1)
+			if err != nil || end {
+				return end, err
+			}
+			continue
+		case 11: //return
+			return false, nil
+		case 14: //endchar, an accented char (seac) is not supported and keeps the base outline only
+			if !cs.width && (len(s) == 1 || len(s) == 5) {
+				cs.width = true
+			}
+			return true, nil
+		case 12:
+			if i >= len(code) {
+				return false, errors.New(FontFormatError)
+			}
+			cs.flex(int(code[i]), s)
+			i++
+		default:
+			return false, errors.New(FontFormatError)
+		}
+		cs.stack = cs.stack[:0]
+	}
+	return false, nil
+}
+
+// flex internal, the two byte operators, only the flex curves draw, the rest are ignored
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) flex(op int, s []float64) {
+	switch {
+	case op == 35 && len(s) >= 12: //flex
+		cs.curveTo(s[0], s[1], s[2], s[3], s[4], s[5])
+		cs.curveTo(s[6], s[7], s[8], s[9], s[10], s[11])
+	case op == 34 && len(s) >= 7: //hflex
+		cs.curveTo(s[0], 0, s[1], s[2], s[3], 0)
+		cs.curveTo(s[4], 0, s[5], -s[2], s[6], 0)
+	case op == 36 && len(s) >= 9: //hflex1
+		cs.curveTo(s[0], s[1], s[2], s[3], s[4], 0)
+		cs.curveTo(s[5], 0, s[6], s[7], s[8], -(s[1] + s[3] + s[7]))
+	case op == 37 && len(s) >= 11: //flex1, the last point is horizontal or vertical by the larger distance
+		dx := s[0] + s[2] + s[4] + s[6] + s[8]
+		dy := s[1] + s[3] + s[5] + s[7] + s[9]
+		cs.curveTo(s[0], s[1], s[2], s[3], s[4], s[5])
+		if math.Abs(dx) > math.Abs(dy) {
+			cs.curveTo(s[6], s[7], s[8], s[9], s[10], -dy)
+		} else {
+			cs.curveTo(s[6], s[7], s[8], s[9], -dx, s[10])
+		}
+	}
+}
+
+// stems internal, counts the stem hints of the operands, an odd count starts with the width
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) stems() {
+	if !cs.width && len(cs.stack)%2 == 1 {
+		cs.stack = cs.stack[1:]
+	}
+	cs.width = true
+	cs.nStems += len(cs.stack) / 2
+}
+
+// dropWidth internal, removes the width in front of the n operands of the first moveto
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) dropWidth(n int) []float64 {
+	if !cs.width && len(cs.stack) > n {
+		cs.stack = cs.stack[1:]
+	}
+	cs.width = true
+	return cs.stack
+}
+
+// point internal, the current point in pixel coordinates, y down from the baseline
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) point(x, y float64) [2]float64 {
+	return [2]float64{x * cs.scale, -y * cs.scale}
+}
+
+// closeContour internal, ends the current contour, polygons are implicitly closed
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) closeContour() {
+	if len(cs.contour) > 1 {
+		cs.contours = append(cs.contours, cs.contour)
+	}
+	cs.contour = nil
+}
+
+// moveTo internal, starts a new contour relative to the current point
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) moveTo(dx, dy float64) {
+	cs.closeContour()
+	cs.x, cs.y = cs.x+dx, cs.y+dy
+	cs.contour = [][2]float64{cs.point(cs.x, cs.y)}
+}
+
+// lineTo internal, a line relative to the current point
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) lineTo(dx, dy float64) {
+	cs.x, cs.y = cs.x+dx, cs.y+dy
+	cs.contour = append(cs.contour, cs.point(cs.x, cs.y))
+}
+
+// curveTo internal, flattens a cubic bezier, every point relative to the one before
+// ----------------------------------------------------------------------------------------------------------------------
+func (cs *cffCharString) curveTo(dx1, dy1, dx2, dy2, dx3, dy3 float64) {
+	x0, y0 := cs.x, cs.y
+	x1, y1 := x0+dx1, y0+dy1
+	x2, y2 := x1+dx2, y1+dy2
+	x3, y3 := x2+dx3, y2+dy3
+	steps := 8
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		u := 1 - t
+		x := u*u*u*x0 + 3*u*u*t*x1 + 3*u*t*t*x2 + t*t*t*x3
+		y := u*u*u*y0 + 3*u*u*t*y1 + 3*u*t*t*y2 + t*t*t*y3
+		cs.contour = append(cs.contour, cs.point(x, y))
+	}
+	cs.x, cs.y = x3, y3
+}
@@ -0,0 +1,413 @@
+package pixelding
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	ChartLine = iota
+	ChartBar
+	ChartStackedBar
+	ChartArea
+)
+
+// PixelSeries is one data series of a chart. A Color of 0 selects a default color
+// matching the current color mode
+type PixelSeries struct {
+	Name  string
+	Color uint32
+	Data  []float64
+}
+
+// PixelChart describes a line, bar or area chart. With Min and Max equal the value axis
+// is scaled automatically. NaN and infinite values are left out, a line or area is broken there.
+// Labels are the category labels below the chart.
+// If a Font is given all texts are printed with it, otherwise the texts are put into the
+// text overlay (see Text) for the color modes, or for ModeNoColor by ChartText after rendering
+type PixelChart struct {
+	Type     int
+	Series   []PixelSeries
+	Min, Max float64
+	Ticks    int
+	Labels   []string
+	Legend   bool
+	Grid     bool
+	Font     *PixelFont
+}
+
+// chartLayout internal, the computed chart geometry in paint area coordinates
+type chartLayout struct {
+	min, max, step float64
+	decimals       int
+	px0, py0       int
+	px1, py1       int
+	cw, ch, box    int
+	ticks          []float64
+}
+
+// chartFills internal, the fill patterns to separate bars and areas in ModeNoColor
+var chartFills = []uint64{0, FillHatchDPattern, FillCheckerPattern, FillHatchVPattern, FillCrossPattern, FillDotPattern}
+
+// Chart draws the chart into the rectangle x0,y0 to x1,y1. Axis, ticks and legend are
+// drawn with the current color
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Chart(chart *PixelChart, x0, y0, x1, y1 int) {
+	if len(chart.Series) == 0 {
+		return
+	}
+	acolor := p.acolor
+	fillstyle := p.fillstyle
+
+	l := p.chartLayout(chart, x0, y0, x1, y1)
+	if l.px1-l.px0 < 1 || l.py1-l.py0 < 1 {
+		return
+	}
+
+	//axis, ticks and grid
+	p.Line(l.px0-1, l.py0, l.px0-1, l.py1+1, true)
+	p.Line(l.px0-1, l.py1+1, l.px1, l.py1+1, true)
+	for _, v := range l.ticks {
+		y := l.valueY(v)
+		p.Line(l.px0-3, y, l.px0-2, y, true)
+		if chart.Grid && y <= l.py1 {
+			p.DotLine(l.px0, y, l.px1, y, true, Dot1x3Pattern)
+		}
+	}
+
+	n := chart.points()
+	base := l.valueY(math.Max(l.min, math.Min(l.max, 0)))
+	switch chart.Type {
+	case ChartLine, ChartArea:
+		for i, s := range chart.Series {
+			p.chartStyle(i, s)
+			//every run of finite values is drawn on its own
+			var pts [][2]int
+			for j := 0; j <= len(s.Data); j++ {
+				if j < len(s.Data) && finite(s.Data[j]) {
+					pts = append(pts, [2]int{l.pointX(j, n), l.valueY(s.Data[j])})
+					continue
+				}
+				p.chartRun(pts, base, chart.Type == ChartArea)
+				pts = nil
+			}
+		}
+
+	case ChartBar, ChartStackedBar:
+		slot := float64(l.px1-l.px0+1) / float64(n)
+		group := math.Max(1, slot*0.8)
+		bw := group
+		if chart.Type == ChartBar {
+			bw = group / float64(len(chart.Series))
+		}
+		pos := make([]float64, n)
+		neg := make([]float64, n)
+		for i, s := range chart.Series {
+			p.chartStyle(i, s)
+			for j, v := range s.Data {
+				if !finite(v) {
+					continue
+				}
+				bx := float64(l.px0) + slot*float64(j) + (slot-group)/2
+				from := 0.0
+				if chart.Type == ChartBar {
+					bx += bw * float64(i)
+				} else if v >= 0 {
+					from = pos[j]
+					pos[j] += v
+					v = pos[j]
+				} else {
+					from = neg[j]
+					neg[j] += v
+					v = neg[j]
+				}
+				ya := l.valueY(from)
+				yb := l.valueY(v)
+				if ya > yb {
+					ya, yb = yb, ya
+				}
+				p.Rectangle(int(bx), ya, maxInt(int(bx), int(bx+bw)-1), yb, true, true)
+			}
+		}
+	}
+
+	if chart.Legend {
+		x := l.px0
+		for i, s := range chart.Series {
+			p.chartStyle(i, s)
+			p.Rectangle(x, y0, x+l.box-1, y0+l.box-1, true, true)
+			x += l.box + l.cw + p.chartTextWidth(chart, l, s.Name) + 2*l.cw
+		}
+	}
+	p.acolor = acolor
+	p.fillstyle = fillstyle
+	if chart.Font != nil || p.colorrender != ModeNoColor {
+		p.chartTexts(chart, l, x0, y0, false)
+	}
+}
+
+// ChartText puts the tick labels, category labels and legend names of a chart without
+// font into the rendered buffer, use it after rendering in ModeNoColor
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) ChartText(chart *PixelChart, x0, y0, x1, y1 int) {
+	if len(chart.Series) == 0 || chart.Font != nil {
+		return
+	}
+	p.chartTexts(chart, p.chartLayout(chart, x0, y0, x1, y1), x0, y0, true)
+}
+
+// chartTexts internal
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) chartTexts(chart *PixelChart, l chartLayout, x0, y0 int, buffer bool) {
+	text := func(x, y int, s string) {
+		switch {
+		case buffer:
+			p.TextBuffer(x, y, s, true)
+		case chart.Font != nil:
+			p.FontPrint(chart.Font, x, y, s, true)
+		default:
+			x, y = p.scale(x, y)
+			p.Text(x, y, s)
+		}
+	}
+	for _, v := range l.ticks {
+		s := strconv.FormatFloat(v, 'f', l.decimals, 64)
+		text(l.px0-3-p.chartTextWidth(chart, l, s)-1, l.valueY(v)-l.ch/2, s)
+	}
+	n := chart.points()
+	for j, s := range chart.Labels {
+		if j >= n {
+			break
+		}
+		x := l.pointX(j, n)
+		if chart.Type == ChartBar || chart.Type == ChartStackedBar {
+			slot := float64(l.px1-l.px0+1) / float64(n)
+			x = l.px0 + int(slot*(float64(j)+0.5))
+		}
+		text(x-p.chartTextWidth(chart, l, s)/2, l.py1+3, s)
+	}
+	if chart.Legend {
+		x := l.px0
+		for _, s := range chart.Series {
+			text(x+l.box+l.cw, y0+(l.box-l.ch)/2, s.Name)
+			x += l.box + l.cw + p.chartTextWidth(chart, l, s.Name) + 2*l.cw
+		}
+	}
+}
+
+// chartLayout internal, scales the value axis and splits the rectangle into margins and plot area
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) chartLayout(chart *PixelChart, x0, y0, x1, y1 int) chartLayout {
+	l := chartLayout{}
+	switch {
+	case chart.Font != nil:
+		l.cw = (1 + p.faspectX) * 4
+		l.ch = (chart.Font.LineHeight() + 1) * (1 + p.faspectY)
+	case p.colorrender == ModeNoColor:
+		l.cw = 2 - p.aspectX
+		l.ch = 2 - p.aspectY
+	default:
+		l.cw = 1
+		l.ch = 2
+	}
+
+	lo, hi := chart.valueRange()
+	fixed := chart.Min != chart.Max && finite(chart.Min) && finite(chart.Max)
+	if fixed {
+		lo, hi = math.Min(chart.Min, chart.Max), math.Max(chart.Min, chart.Max)
+	}
+	ticks := chart.Ticks
+	if ticks < 2 {
+		ticks = 5
+	}
+	//flat data spans its own magnitude, the step is at least a tiny part of the values so adding it changes a value
+	span := hi - lo
+	if span <= 0 {
+		span = math.Max(float64(ticks-1), math.Abs(hi))
+	}
+	span = math.Max(span, math.Max(math.Abs(lo), math.Abs(hi))*1e-9)
+	l.step = niceNum(span / float64(ticks-1))
+	if fixed {
+		l.min, l.max = lo, hi
+	} else {
+		l.min = math.Floor(lo/l.step) * l.step
+		l.max = math.Ceil(hi/l.step) * l.step
+	}
+	if l.max <= l.min {
+		l.max = l.min + l.step
+	}
+	l.decimals = maxInt(0, -int(math.Floor(math.Log10(l.step))))
+	first := math.Ceil(l.min/l.step) * l.step
+	for i := 0; i <= 2*ticks; i++ {
+		v := first + float64(i)*l.step
+		if v > l.max+l.step/1e6 {
+			break
+		}
+		l.ticks = append(l.ticks, math.Round(v/l.step)*l.step)
+	}
+
+	labelw := 0
+	for _, v := range l.ticks {
+		labelw = maxInt(labelw, p.chartTextWidth(chart, l, strconv.FormatFloat(v, 'f', l.decimals, 64)))
+	}
+	l.px0 = x0 + labelw + 4
+	l.px1 = x1
+	l.py0 = y0 + l.ch/2
+	l.box = maxInt(l.ch, 4)
+	if chart.Legend {
+		l.py0 = y0 + l.box + l.ch/2 + 1
+	}
+	l.py1 = y1 - 1
+	if len(chart.Labels) > 0 {
+		l.py1 = y1 - l.ch - 3
+	}
+	return l
+}
+
+// chartTextWidth internal, width of a text in paint area coordinates
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) chartTextWidth(chart *PixelChart, l chartLayout, s string) int {
+	if chart.Font == nil {
+		return len([]rune(s)) * l.cw
+	}
+	w, _ := p.MeasureText(chart.Font, s)
+	return w
+}
+
+// valueY internal, maps a value to the y coordinate of the plot area
+// ----------------------------------------------------------------------------------------------------------------------
+func (l chartLayout) valueY(v float64) int {
+	f := (v - l.min) / (l.max - l.min)
+	return l.py1 - int(math.Round(f*float64(l.py1-l.py0)))
+}
+
+// pointX internal, maps the data index to the x coordinate of the plot area
+// ----------------------------------------------------------------------------------------------------------------------
+func (l chartLayout) pointX(i, n int) int {
+	if n < 2 {
+		return (l.px0 + l.px1) / 2
+	}
+	return l.px0 + int(math.Round(float64(i)*float64(l.px1-l.px0)/float64(n-1)))
+}
+
+// points internal, the number of categories or data points
+// ----------------------------------------------------------------------------------------------------------------------
+func (c *PixelChart) points() int {
+	n := len(c.Labels)
+	for _, s := range c.Series {
+		n = maxInt(n, len(s.Data))
+	}
+	return maxInt(n, 1)
+}
+
+// valueRange internal, the minimum and maximum value of all series, bars and areas always include zero
+// ----------------------------------------------------------------------------------------------------------------------
+func (c *PixelChart) valueRange() (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	if c.Type != ChartLine {
+		lo, hi = 0, 0
+	}
+	if c.Type == ChartStackedBar {
+		n := c.points()
+		pos := make([]float64, n)
+		neg := make([]float64, n)
+		for _, s := range c.Series {
+			for j, v := range s.Data {
+				if !finite(v) {
+					continue
+				}
+				if v >= 0 {
+					pos[j] += v
+				} else {
+					neg[j] += v
+				}
+			}
+		}
+		for j := range pos {
+			lo = math.Min(lo, neg[j])
+			hi = math.Max(hi, pos[j])
+		}
+		return lo, hi
+	}
+	for _, s := range c.Series {
+		for _, v := range s.Data {
+			if finite(v) {
+				lo = math.Min(lo, v)
+				hi = math.Max(hi, v)
+			}
+		}
+	}
+	if math.IsInf(lo, 0) {
+		return 0, 1
+	}
+	return lo, hi
+}
+
+// chartRun internal, draws the points of a line, an area down to the base line
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) chartRun(pts [][2]int, base int, area bool) {
+	if len(pts) == 0 {
+		return
+	}
+	if area && len(pts) > 1 {
+		poly := append([][2]int{{pts[0][0], base}}, pts...)
+		poly = append(poly, [2]int{pts[len(pts)-1][0], base})
+		p.Polygon(poly, true, true)
+	}
+	for j := 1; j < len(pts); j++ {
+		p.Line(pts[j-1][0], pts[j-1][1], pts[j][0], pts[j][1], true)
+	}
+	if len(pts) == 1 {
+		p.Pixel(pts[0][0], pts[0][1], true)
+	}
+}
+
+// chartStyle internal, sets color and fill style for series i
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) chartStyle(i int, s PixelSeries) {
+	p.fillstyle = nil
+	c := s.Color
+	if c == 0 {
+		switch p.colorrender {
+		case ModeTrueColor:
+			c = HSV(float64((i*137)%360), 0.7, 1)
+		case ModePaletteColor:
+			c = uint32(9 + i%6)
+		case Mode16Color:
+			c = uint32(ColorRed + i%6)
+		default:
+			c = 1
+		}
+	}
+	p.acolor = c
+	if p.colorrender == ModeNoColor && i%len(chartFills) > 0 {
+		p.fillstyle = PatternFill(chartFills[i%len(chartFills)], c)
+	}
+}
+
+// finite internal, true for a value neither NaN nor infinite
+// ----------------------------------------------------------------------------------------------------------------------
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// niceNum internal, rounds x to a 1, 2, 5 multiple of a power of ten
+// ----------------------------------------------------------------------------------------------------------------------
+func niceNum(x float64) float64 {
+	if x <= 0 || math.IsNaN(x) || math.IsInf(x, 0) {
+		return 1
+	}
+	exp := math.Floor(math.Log10(x))
+	f := x / math.Pow(10, exp)
+	switch {
+	case f < 1.5:
+		f = 1
+	case f < 3:
+		f = 2
+	case f < 7:
+		f = 5
+	default:
+		f = 10
+	}
+	return f * math.Pow(10, exp)
+}
@@ -0,0 +1,113 @@
+package pixelding
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChartLayoutTicks(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	series := func(data ...[]float64) []PixelSeries {
+		var s []PixelSeries
+		for _, d := range data {
+			s = append(s, PixelSeries{Data: d})
+		}
+		return s
+	}
+	tests := []struct {
+		name     string
+		chart    PixelChart
+		min, max float64
+		ticks    []float64
+		decimals int
+	}{
+		{"line", PixelChart{Type: ChartLine, Series: series([]float64{3, 7, 2.5, 9})}, 2, 10,
+			[]float64{2, 4, 6, 8, 10}, 0},
+		{"bar includes zero", PixelChart{Type: ChartBar, Series: series([]float64{3, 7})}, 0, 8,
+			[]float64{0, 2, 4, 6, 8}, 0},
+		{"stacked", PixelChart{Type: ChartStackedBar, Series: series([]float64{1, 2}, []float64{3, -1})}, -1, 4,
+			[]float64{-1, 0, 1, 2, 3, 4}, 0},
+		{"min max", PixelChart{Series: series([]float64{5}), Min: 0, Max: 1, Ticks: 3}, 0, 1,
+			[]float64{0, 0.5, 1}, 1},
+		{"min max swapped", PixelChart{Series: series([]float64{5}), Min: 10, Max: 0}, 0, 10,
+			[]float64{0, 2, 4, 6, 8, 10}, 0},
+		{"flat", PixelChart{Series: series([]float64{5, 5})}, 5, 6, []float64{5, 6}, 0},
+		{"flat zero", PixelChart{Series: series([]float64{0})}, 0, 1, []float64{0, 1}, 0},
+		{"flat huge", PixelChart{Series: series([]float64{1e17, 1e17})}, 1e17, 1.2e17, []float64{1e17, 1.2e17}, 0},
+		{"huge close", PixelChart{Series: series([]float64{1e17, 1e17 + 64})}, 1e17, 1e17 + 2e7,
+			[]float64{1e17, 1e17 + 2e7}, 0},
+		{"non finite", PixelChart{Series: series([]float64{nan, 2, inf, 6, -inf})}, 2, 6,
+			[]float64{2, 3, 4, 5, 6}, 0},
+		{"all nan", PixelChart{Series: series([]float64{nan})}, 0, 1, []float64{0, 0.2, 0.4, 0.6, 0.8, 1}, 1},
+		{"nan min", PixelChart{Series: series([]float64{0, 8}), Min: nan, Max: 100}, 0, 8,
+			[]float64{0, 2, 4, 6, 8}, 0},
+	}
+	for _, tt := range tests {
+		p := New(120, 60)
+		l := p.chartLayout(&tt.chart, 0, 0, 119, 59)
+		same := len(l.ticks) == len(tt.ticks)
+		for i := 0; same && i < len(l.ticks); i++ {
+			same = math.Abs(l.ticks[i]-tt.ticks[i]) <= l.step*1e-9
+		}
+		if l.min != tt.min || l.max != tt.max || !same || l.decimals != tt.decimals {
+			t.Errorf("%s: range %v..%v ticks %v decimals %d, want %v..%v %v %d", tt.name, l.min, l.max, l.ticks,
+				l.decimals, tt.min, tt.max, tt.ticks, tt.decimals)
+		}
+	}
+}
+
+func TestChartLayoutGeometry(t *testing.T) {
+	p := New(120, 60)
+	chart := &PixelChart{Series: []PixelSeries{{Name: "a", Data: []float64{0, 5, 10}}}, Labels: []string{"x", "y", "z"},
+		Legend: true}
+	l := p.chartLayout(chart, 10, 5, 109, 54)
+	//the widest tick label "10" is two chars
+	if l.px0 != 10+2*l.cw+4 || l.px1 != 109 || l.py0 != 5+l.box+l.ch/2+1 || l.py1 != 54-l.ch-3 {
+		t.Errorf("plot area %d,%d %d,%d", l.px0, l.py0, l.px1, l.py1)
+	}
+	if l.valueY(l.min) != l.py1 || l.valueY(l.max) != l.py0 {
+		t.Errorf("value axis %d..%d, want %d..%d", l.valueY(l.min), l.valueY(l.max), l.py1, l.py0)
+	}
+	if l.pointX(0, 3) != l.px0 || l.pointX(2, 3) != l.px1 || l.pointX(0, 1) != (l.px0+l.px1)/2 {
+		t.Errorf("points %d %d %d", l.pointX(0, 3), l.pointX(2, 3), l.pointX(0, 1))
+	}
+}
+
+func TestChartDraw(t *testing.T) {
+	//huge and non finite values draw in bounded time
+	p := New(60, 30)
+	p.Chart(&PixelChart{Series: []PixelSeries{{Data: []float64{1e17, 1e17}}}}, 0, 0, 59, 29)
+	p.Chart(&PixelChart{Series: []PixelSeries{{Data: []float64{math.Inf(1), math.NaN()}}}}, 0, 0, 59, 29)
+	p.Chart(&PixelChart{Series: []PixelSeries{{Data: []float64{-1e308, 1e308}}}}, 0, 0, 59, 29)
+
+	//a NaN breaks the line
+	p = New(60, 30)
+	chart := &PixelChart{Series: []PixelSeries{{Data: []float64{2, math.NaN(), 8, 8}}}, Min: 0, Max: 10}
+	p.Chart(chart, 0, 0, 59, 29)
+	l := p.chartLayout(chart, 0, 0, 59, 29)
+	got := pixels(&p)
+	if !got[[2]int{l.pointX(0, 4), l.valueY(2)}] || !got[[2]int{l.pointX(3, 4), l.valueY(8)}] {
+		t.Error("line points missing")
+	}
+	for x := l.pointX(0, 4) + 1; x < l.pointX(2, 4); x++ {
+		for y := l.py0; y <= l.py1; y++ {
+			if got[[2]int{x, y}] {
+				t.Fatalf("pixel %d,%d set between the runs", x, y)
+			}
+		}
+	}
+
+	//a bar from zero up to its value
+	p = New(60, 30)
+	chart = &PixelChart{Type: ChartBar, Series: []PixelSeries{{Data: []float64{4, math.NaN()}}}, Min: 0, Max: 8}
+	p.Chart(chart, 0, 0, 59, 29)
+	l = p.chartLayout(chart, 0, 0, 59, 29)
+	got = pixels(&p)
+	x := l.px0 + (l.px1-l.px0+1)/4
+	if !got[[2]int{x, l.valueY(2)}] || !got[[2]int{x, l.valueY(4)}] || got[[2]int{x, l.valueY(6)}] {
+		t.Error("bar height")
+	}
+	if got[[2]int{l.px0 + 3*(l.px1-l.px0+1)/4, l.valueY(1)}] {
+		t.Error("NaN bar drawn")
+	}
+}
@@ -1,14 +1,11 @@
 package pixelding
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/bits"
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -40,6 +37,9 @@ const OutOfBoundsError = "out of bounds"
 const AlreadySetError = "already set"
 const DimensionError = "dimension error"
 const ColormodeError = "colormode error"
+const PictureError = "picture error"
+const PictureDataError = "picture data error"
+const PictureSegmentError = "picture segment error"
 
 const RegSplitter = "[MmLlHhVvZzCcSsQqTtAa]|[+-]?\\d+\\.\\d+|[+-]?\\d+|[+-]?\\.\\d+"
 
@@ -60,9 +60,18 @@ type PixelDING struct {
 	debug          bool
 	invert         bool
 	toggle         bool
+	svgstrict      bool
+	svgfill        bool
+	fillrule       int
+	fillstyle      *PixelFill
 	acolor         uint32
 	bcolor         uint32
 	colorrender    int
+	rendermode     int
+	fallback       bool
+	imgset         uint32
+	imgunset       uint32
+	fileformat     int
 	LastError      error
 	buffer         []string
 	fonts          map[string]*PixelFont
@@ -77,21 +86,26 @@ type PixelStamp struct {
 }
 
 type PixelPicture struct {
-	Mode     int      `json:"mode"`
-	ColorKey uint32   `json:"colorKey"`
-	SizeX    int      `json:"sizeX"`
-	SizeY    int      `json:"sizeY"`
-	SegX     int      `json:"segX"`
-	SegY     int      `json:"segY"`
-	Data     []uint32 `json:"data"`
+	Mode        int      `json:"mode"`
+	ColorKey    uint32   `json:"colorKey"`
+	UseColorKey bool     `json:"useColorKey,omitempty"`
+	Alpha       bool     `json:"alpha,omitempty"`
+	SizeX       int      `json:"sizeX"`
+	SizeY       int      `json:"sizeY"`
+	SegX        int      `json:"segX"`
+	SegY        int      `json:"segY"`
+	Data        []uint32 `json:"data"`
 }
 
 type PixelFont struct {
-	Prepared bool              `json:"prepared"`
-	sizex    int               `json:"-"`
-	sizey    int               `json:"-"`
-	numchar  int               `json:"-"`
-	Chars    map[int]PixelChar `json:"chars"`
+	Prepared bool                `json:"prepared"`
+	Ascent   int                 `json:"ascent,omitempty"`
+	Descent  int                 `json:"descent,omitempty"`
+	Kerning  map[int]map[int]int `json:"kerning,omitempty"`
+	sizex    int                 `json:"-"`
+	sizey    int                 `json:"-"`
+	numchar  int                 `json:"-"`
+	Chars    map[int]PixelChar   `json:"chars"`
 }
 
 type PixelFontInfo struct {
@@ -108,6 +122,7 @@ type PixelChar struct {
 	Len     int      `json:"len"`
 	GN      int      `json:"gn"`
 	GA      int      `json:"ga"`
+	Advance int      `json:"advance,omitempty"`
 	Data    []uint64 `json:"data"`
 }
 
@@ -124,6 +139,8 @@ func New(dimensions ...int) PixelDING {
 	x.SetStep(0)
 	x.acolor = 1
 	x.bcolor = 0
+	x.imgset = 0xFFFFFF
+	x.imgunset = 0x000000
 	x.fonts = make(map[string]*PixelFont)
 	x.stamps = make(map[string]*PixelStamp)
 	x.pics = make(map[string]*PixelPicture)
@@ -200,12 +217,14 @@ func (p *PixelDING) Y() int {
 	return p.sizeY
 }
 
-// SaveFont save a font to disk
+// SaveFont save a font to disk in the FileFormat
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) SaveFont(name string, font *PixelFont, permissions os.FileMode) error {
 
-	buf, err := json.Marshal(font)
-	err = os.WriteFile(name, buf, permissions)
+	buf, err := p.marshal(font)
+	if err == nil {
+		err = os.WriteFile(name, buf, permissions)
+	}
 	if err != nil {
 		p.LastError = err
 		return err
@@ -213,7 +232,7 @@ func (p *PixelDING) SaveFont(name string, font *PixelFont, permissions os.FileMo
 	return nil
 }
 
-// LoadFont load a font into pixelDING font object
+// LoadFont load a font into pixelDING font object, JSON or binary
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) LoadFont(name string) *PixelFont {
 	x := PixelFont{}
@@ -222,7 +241,7 @@ func (p *PixelDING) LoadFont(name string) *PixelFont {
 		p.LastError = err
 		return nil
 	}
-	err = json.Unmarshal(buf, &x)
+	err = unmarshal(buf, &x)
 	if err != nil {
 		p.LastError = err
 		return nil
@@ -230,11 +249,13 @@ func (p *PixelDING) LoadFont(name string) *PixelFont {
 	return &x
 }
 
-// SaveStamp save a stamp to disk
+// SaveStamp save a stamp to disk in the FileFormat
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) SaveStamp(name string, stamp *PixelStamp, permissions os.FileMode) error {
-	buf, err := json.Marshal(stamp)
-	err = os.WriteFile(name, buf, permissions)
+	buf, err := p.marshal(stamp)
+	if err == nil {
+		err = os.WriteFile(name, buf, permissions)
+	}
 	if err != nil {
 		p.LastError = err
 		return err
@@ -242,7 +263,7 @@ func (p *PixelDING) SaveStamp(name string, stamp *PixelStamp, permissions os.Fil
 	return nil
 }
 
-// LoadStamp load a stamp into pixelDING stamp object
+// LoadStamp load a stamp into pixelDING stamp object, JSON or binary
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) LoadStamp(name string) *PixelStamp {
 	x := PixelStamp{}
@@ -251,7 +272,7 @@ func (p *PixelDING) LoadStamp(name string) *PixelStamp {
 		p.LastError = err
 		return nil
 	}
-	err = json.Unmarshal(buf, &x)
+	err = unmarshal(buf, &x)
 	if err != nil {
 		p.LastError = err
 		return nil
@@ -259,11 +280,13 @@ func (p *PixelDING) LoadStamp(name string) *PixelStamp {
 	return &x
 }
 
-// SavePicture Save a picture that was created ot converted into memory
+// SavePicture Save a picture that was created ot converted into memory in the FileFormat
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) SavePicture(name string, picture *PixelPicture, permissions os.FileMode) error {
-	buf, err := json.Marshal(picture)
-	err = os.WriteFile(name, buf, permissions)
+	buf, err := p.marshal(picture)
+	if err == nil {
+		err = os.WriteFile(name, buf, permissions)
+	}
 	if err != nil {
 		p.LastError = err
 		return err
@@ -271,7 +294,7 @@ func (p *PixelDING) SavePicture(name string, picture *PixelPicture, permissions
 	return nil
 }
 
-// LoadPicture Load a picture into pixelDING picture object
+// LoadPicture Load a picture into pixelDING picture object, JSON or binary
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) LoadPicture(name string) *PixelPicture {
 	x := PixelPicture{}
@@ -280,7 +303,7 @@ func (p *PixelDING) LoadPicture(name string) *PixelPicture {
 		p.LastError = err
 		return nil
 	}
-	err = json.Unmarshal(buf, &x)
+	err = unmarshal(buf, &x)
 	if err != nil {
 		p.LastError = err
 		return nil
@@ -288,10 +311,11 @@ func (p *PixelDING) LoadPicture(name string) *PixelPicture {
 	return &x
 }
 
-// FontPrint Print a text into pixelDING with given font at x,y
+// FontPrint Print a text into pixelDING with given font at x,y, the top left of the text line. The chars are
+// placed on the baseline of the font and moved by their OffsetX, OffsetY
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) FontPrint(font *PixelFont, x0, y0 int, text string, set bool, param ...int) {
-	ls := 0
+	ls := -1
 	sx := x0
 	sy := y0
 	v := 0
@@ -304,17 +328,62 @@ func (p *PixelDING) FontPrint(font *PixelFont, x0, y0 int, text string, set bool
 	//	return
 	//}
 	for _, z := range text {
+		char := font.Chars[int(z)]
 		v = 0
-		if ls != 0 && font.Chars[int(z)].GA == ls {
-			v = -1
+		if ls >= 0 {
+			v = font.Kern(ls, int(z))
 		}
-		p.fontStamp(sx+v, sy, font.Chars[int(z)].Data, set, p.faspectX, p.faspectY)
-		sx = sx + font.Chars[int(z)].SizeX + 1 + v + offset
+		p.fontStamp(sx+(v+char.OffsetX)*(1+p.faspectX), sy+font.top(char)*(1+p.faspectY), char.Data, set,
+			p.faspectX, p.faspectY)
+		sx = sx + char.advance() + v + offset
 		if p.faspectX > 0 {
-			sx = sx + font.Chars[int(z)].SizeX + 1 + v
+			sx = sx + char.advance() + v
 		}
-		ls = font.Chars[int(z)].GN
+		ls = int(z)
+	}
+}
+
+// advance internal, returns the advance width of a char, SizeX plus one pixel space if not set
+// ----------------------------------------------------------------------------------------------------------------------
+func (f PixelChar) advance() int {
+	if f.Advance > 0 {
+		return f.Advance
+	}
+	return f.SizeX + 1
+}
+
+// Baseline returns the distance from the top of a text line to the baseline, this is Ascent or for
+// fonts without Ascent the bottom of the lowest char
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *PixelFont) Baseline() int {
+	if f.Ascent > 0 {
+		return f.Ascent
+	}
+	b := 0
+	for _, char := range f.Chars {
+		b = maxInt(b, char.OffsetY+len(char.Data))
+	}
+	return b
+}
+
+// LineHeight returns the height of a text line, Ascent plus Descent or for fonts without Ascent the
+// bottom of the lowest char
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *PixelFont) LineHeight() int {
+	if f.Ascent > 0 {
+		return f.Ascent + f.Descent
 	}
+	return f.Baseline()
+}
+
+// top internal, returns the first row of a char relative to the top of the text line. With Ascent the
+// chars stand on the baseline and OffsetY moves them down, otherwise OffsetY is counted from the top
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *PixelFont) top(char PixelChar) int {
+	if f.Ascent > 0 {
+		return f.Ascent - len(char.Data) + char.OffsetY
+	}
+	return char.OffsetY
 }
 
 // Prepare This is a compression option to reduce the saved size on disk
@@ -339,6 +408,39 @@ func (f *PixelFont) AddChar(ix int, char PixelChar) {
 	f.Chars[ix] = char
 }
 
+// AddKerning adds a kerning pair to a pixelDING font object, the right char is moved by adjust pixels
+// if it follows the left char, 0 removes the pair
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *PixelFont) AddKerning(left, right, adjust int) {
+	if adjust == 0 {
+		delete(f.Kerning[left], right)
+		if len(f.Kerning[left]) == 0 {
+			delete(f.Kerning, left)
+		}
+		return
+	}
+	if f.Kerning == nil {
+		f.Kerning = map[int]map[int]int{}
+	}
+	if f.Kerning[left] == nil {
+		f.Kerning[left] = map[int]int{}
+	}
+	f.Kerning[left][right] = adjust
+}
+
+// Kern returns the pixel adjustment of the right char following the left char. Without a kerning pair
+// the chars move one pixel together if the GA group of the right char is the GN group of the left char
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *PixelFont) Kern(left, right int) int {
+	if k, ok := f.Kerning[left][right]; ok {
+		return k
+	}
+	if gn := f.Chars[left].GN; gn != 0 && f.Chars[right].GA == gn {
+		return -1
+	}
+	return 0
+}
+
 // PrepareFont this is a compression option to reduce the saved size on disk
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) PrepareFont(x PixelFont) *PixelFont {
@@ -641,6 +743,13 @@ func (p *PixelDING) Invert(b bool) {
 	p.invert = b
 }
 
+// SVGStrict enables the strict mode for SVGPath, in strict mode a path that fails
+// validation is not drawn at all. The parse error is always stored in LastError
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) SVGStrict(b bool) {
+	p.svgstrict = b
+}
+
 // Debug switches some debug messages on (experimental)
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) Debug(b bool) {
@@ -699,66 +808,102 @@ func (s *PixelStamp) Y() int {
 // Picture draws a picture obeject at the x,y coordinates given.
 // if a segment is specified the function is painting only that segment
 // The calculation of the segment is done only via the segment size of the picture
-// SegX and SegY which need to be specified in the picture itself
-// ----------------------------------------------------------------------------------------------------------------------
-func (p *PixelDING) Picture(picture *PixelPicture, x0, y0 int, segment int) {
-	xdehn := 0
-	ydehn := 0
-	xstart := 0
-	ystart := 0
-	if picture.SegX > 0 {
-		xdehn = picture.SizeX / picture.SegX
-	}
-	if picture.SegY > 0 {
-		ydehn = picture.SizeY / picture.SegY
-	}
-
-	if segment > xdehn*ydehn {
-		segment = 1
-	}
-
-	if segment > 0 {
-		//zero based calculation
-		segment--
-		xstart = segment % xdehn
-		ystart = segment / xdehn
-
-		ix := (xstart * picture.SegX) + (ystart * picture.SizeX * picture.SegY)
-		jx := ix
-		/*
-			fmt.Println("PicX", picture.SizeX)
-			fmt.Println("PicY", picture.SizeY)
-			fmt.Println("SegX", picture.SegX)
-			fmt.Println("SegY", picture.SegY)
-
-			fmt.Println("Segment", segment)
-			fmt.Println("xdehn", xdehn)
-			fmt.Println("ydehn", ydehn)
-			fmt.Println("xstart", xstart)
-			fmt.Println("ystart", ystart)
-			fmt.Println("ix", ix)
-		*/
-		for i := 0; i < picture.SegY; i++ {
-			for j := 0; j < picture.SegX; j++ {
-				p.PixelC(x0+j, y0+i, picture.Data[ix])
-				ix++
-			}
-			ix = jx + picture.SizeX
-			jx = ix
+// SegX and SegY which need to be specified in the picture itself.
+// Pixels matching a non zero ColorKey are not painted, UseColorKey enables the ColorKey 0 (black) too.
+// Alpha pictures are blended.
+// Inconsistent pictures and segments out of range are not drawn and return an error
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Picture(picture *PixelPicture, x0, y0 int, segment int) error {
+	sx, sy, sw, sh, err := p.pictureSegment(picture, segment)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < sh; i++ {
+		ix := (sy+i)*picture.SizeX + sx
+		for j := 0; j < sw; j++ {
+			p.picturePixel(picture, x0+j, y0+i, picture.Data[ix])
+			ix++
 		}
+	}
+	return nil
+}
 
-	} else {
+// Validate checks a picture for a positive size, matching data length and segment sizes
+// ----------------------------------------------------------------------------------------------------------------------
+func (pic *PixelPicture) Validate() error {
+	if pic == nil || pic.SizeX <= 0 || pic.SizeY <= 0 {
+		return errors.New(PictureError)
+	}
+	if len(pic.Data) != pic.SizeX*pic.SizeY {
+		return errors.New(PictureDataError)
+	}
+	if pic.SegX < 0 || pic.SegY < 0 || pic.SegX > pic.SizeX || pic.SegY > pic.SizeY || (pic.SegX == 0) != (pic.SegY == 0) {
+		return errors.New(PictureSegmentError)
+	}
+	return nil
+}
+
+// pictureSegment internal, validates the picture and returns position and size of the segment
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) pictureSegment(picture *PixelPicture, segment int) (int, int, int, int, error) {
+	if err := picture.Validate(); err != nil {
+		p.LastError = err
+		return 0, 0, 0, 0, err
+	}
+	if segment < 0 || segment > picture.Segments() {
+		p.LastError = errors.New(PictureSegmentError)
+		return 0, 0, 0, 0, p.LastError
+	}
+	x, y, w, h := picture.segmentRect(segment)
+	return x, y, w, h, nil
+}
 
-		ix := 0
+// picturePixel internal, paints one picture pixel, skips the color key and blends alpha pixels
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) picturePixel(picture *PixelPicture, x0, y0 int, color uint32) {
+	x0, y0 = p.scale(x0, y0)
+	p.pictureDot(picture, x0, y0, color)
+}
 
-		for i := 0; i < picture.SizeY; i++ {
-			for j := 0; j < picture.SizeX; j++ {
-				p.PixelC(x0+j, y0+i, picture.Data[ix])
-				ix++
-			}
-		}
+// transparent internal, true if the color is the ColorKey of the picture. A non zero ColorKey is always used,
+// the ColorKey 0 only with UseColorKey, so black pixels of older pictures stay visible
+// ----------------------------------------------------------------------------------------------------------------------
+func (pic *PixelPicture) transparent(color uint32) bool {
+	return color == pic.ColorKey && (pic.UseColorKey || pic.ColorKey != 0)
+}
+
+// pictureDot internal, paints one picture pixel at unscaled coordinates
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) pictureDot(picture *PixelPicture, x0, y0 int, color uint32) {
+	if picture.transparent(color) {
+		return
+	}
+	if picture.Alpha {
+		p.blendPixel(x0, y0, color)
+		return
 	}
+	p.setPixelC(x0, y0, color)
+}
 
+// blendPixel internal, paints an ARGB color. In ModeTrueColor the color is blended with the canvas,
+// in the other modes pixels with at least half alpha are painted
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) blendPixel(x0, y0 int, color uint32) {
+	a := color >> 24
+	switch {
+	case a == 0:
+		return
+	case a == 0xff || (p.colorrender != ModeTrueColor && a >= 0x80):
+		p.setPixelC(x0, y0, color&0xffffff)
+	case p.colorrender == ModeTrueColor:
+		bg := p.getPixelC(x0, y0)
+		var c uint32
+		for shift := 0; shift <= 16; shift += 8 {
+			v := ((color>>shift)&0xff*a + (bg>>shift)&0xff*(0xff-a) + 127) / 0xff
+			c |= v << shift
+		}
+		p.setPixelC(x0, y0, c)
+	}
 }
 
 // Stamp stamps a stamp object at the given x,y coordinates
@@ -904,30 +1049,32 @@ func (p *PixelDING) setBG(c uint32) string {
 // RenderXY renders a given rectangle from pixelDING, given by x1,y1 to x2,y2
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) RenderXY(x1, y1, x2, y2 int) []string {
-	cox := []string{
-		string(32),     // 0
-		string(0x2597), // 1
-		string(0x2596), // 2
-		string(0x2584), // 3
-		string(0x259D), // 4
-		string(0x2590), // 5
-		string(0x259E), // 6
-		string(0x259F), // 7
-		string(0x2598), // 8
-		string(0x259A), // 9
-		string(0x258C), // 10
-		string(0x2599), // 11
-		string(0x2580), // 12
-		string(0x259C), // 13
-		string(0x259B), // 14
-		string(0x2588), // 15
-	}
-	coy := string(0x2584)
+	cox := quadrantGlyphs
+	coy := "\u2584"
 	var afg uint32
 	var abg uint32
 	afg = math.MaxInt32
 	abg = math.MaxInt32
 
+	switch p.rendermode {
+	case RenderBraille:
+		return p.renderCells(x1, y1, x2, y2, 2, 4, brailleGlyph)
+	case RenderSextant:
+		if p.fallback {
+			return p.renderCells(x1, y1, x2, y2, 2, 3, fallbackGlyph(2, 3))
+		}
+		return p.renderCells(x1, y1, x2, y2, 2, 3, sextantGlyph)
+	case RenderOctant:
+		if p.fallback {
+			return p.renderCells(x1, y1, x2, y2, 2, 4, fallbackGlyph(2, 4))
+		}
+		return p.renderCells(x1, y1, x2, y2, 2, 4, octantGlyph)
+	case RenderQuadrant:
+		if p.colorrender != ModeNoColor {
+			return p.renderQuadrants(x1, y1, x2, y2)
+		}
+	}
+
 	p.buffer = []string{}
 	lo := ""
 
@@ -1529,339 +1676,86 @@ func (p *PixelDING) HBar(size int) string {
 	return s
 }
 
-// SVGPath draws a standard SVG path at x,y
-// There is at the moment the arc function missing (work in progress)
+// SVGPath draws a standard SVG path at x,y. The path is parsed by ParseSVGPath, outline and fill (SVGFill)
+// are drawn from the same parsed path. Without SVGStrict a malformed path is drawn up to the error
 // ----------------------------------------------------------------------------------------------------------------------
 func (p *PixelDING) SVGPath(x0, y0 float64, s string, set bool, fscale ...float64) {
-	var x, y float64
-	var lx, ly float64
-	var ix, iy float64
-	var c1x, c1y, c2x, c2y float64
-	var lcx, lcy float64
-	var err error
-	sw := 0
-	cmd := ""
 	scale := 1.0
 	for _, f := range fscale {
 		scale = f
 	}
+	path, err := parseSVGPath(s)
+	if err != nil {
+		p.LastError = err
+		if p.svgstrict {
+			return
+		}
+	}
+	lines := path.PixelPath().Flatten(p.msteps)
+	if p.svgfill {
+		p.fillPolygons(p.pathPolygons(lines, x0, y0, scale), set)
+	}
+	for _, line := range lines {
+		for i := 1; i < len(line); i++ {
+			p.Line(int((line[i-1][0]+x0)*scale), int((line[i-1][1]+y0)*scale), int((line[i][0]+x0)*scale),
+				int((line[i][1]+y0)*scale), set)
+		}
+	}
+}
 
-	re := regexp.MustCompile(RegSplitter)
-	ss := re.FindAllString(s, -1)
-
-	it := 0
-	for _, ssSub := range ss {
-
-		if p.debug {
-			fmt.Println(":::", ssSub, len(ssSub))
-		}
-
-		it++
-
-		_, err = strconv.ParseFloat(ssSub, 64)
-
-		if err != nil { //Not numeric ...
-			switch ssSub {
-			case "M", "m", "L", "l", "V", "v", "H", "h":
-				cmd = ssSub
-				sw = 1
-			case "C", "c":
-				cmd = ssSub
-				sw = 1
-			case "S", "s":
-				cmd = ssSub
-				sw = 1
-			case "Q", "q":
-				cmd = ssSub
-				sw = 1
-			case "T", "t":
-				cmd = ssSub
-				sw = 1
-			case "Z", "z":
-				cmd = ssSub
-				x = ix
-				y = iy
-				sw = 9
-			case "A", "a":
-				cmd = ssSub
-				sw = 1
-			default:
-				if p.debug {
-					fmt.Println("Unknown :", ssSub)
-				}
-				p.LastError = errors.New("Unknown Command")
-			}
-		} else {
-			if sw == 0 {
-				sw = 1
-			}
-			switch {
-
-			case cmd == "M" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				ix = x
-				sw = 2
-			case cmd == "M" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				iy = y
-				sw = 9
-
-			case cmd == "m" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				ix = x
-				sw = 2
-			case cmd == "m" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				iy = y
-				sw = 9
-
-			case cmd == "L" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 2
-			case cmd == "L" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "l" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 2
-			case cmd == "l" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
-
-			case cmd == "v" && sw == 1:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
-			case cmd == "V" && sw == 1:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "h" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 9
-			case cmd == "H" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "c" && sw == 1:
-				c1x, _ = strconv.ParseFloat(ssSub, 64)
-				c1x = lx + c1x
-				sw = 2
-			case cmd == "c" && sw == 2:
-				c1y, _ = strconv.ParseFloat(ssSub, 64)
-				c1y = ly + c1y
-				sw = 3
-			case cmd == "c" && sw == 3:
-				c2x, _ = strconv.ParseFloat(ssSub, 64)
-				c2x = lx + c2x
-				sw = 4
-			case cmd == "c" && sw == 4:
-				c2y, _ = strconv.ParseFloat(ssSub, 64)
-				c2y = ly + c2y
-				sw = 5
-			case cmd == "c" && sw == 5:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 6
-			case cmd == "c" && sw == 6:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
-
-			case cmd == "C" && sw == 1:
-				c1x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 2
-			case cmd == "C" && sw == 2:
-				c1y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 3
-			case cmd == "C" && sw == 3:
-				c2x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 4
-			case cmd == "C" && sw == 4:
-				c2y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 5
-			case cmd == "C" && sw == 5:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 6
-			case cmd == "C" && sw == 6:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "S" && sw == 1:
-				c2x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 2
-			case cmd == "S" && sw == 2:
-				c2y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 3
-			case cmd == "S" && sw == 3:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 4
-			case cmd == "S" && sw == 4:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "s" && sw == 1:
-				c2x, _ = strconv.ParseFloat(ssSub, 64)
-				c2x = lx + c2x
-				sw = 2
-			case cmd == "s" && sw == 2:
-				c2y, _ = strconv.ParseFloat(ssSub, 64)
-				c2y = ly + c2y
-				sw = 3
-			case cmd == "s" && sw == 3:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 4
-			case cmd == "s" && sw == 4:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
-
-			case cmd == "Q" && sw == 1:
-				c1x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 2
-			case cmd == "Q" && sw == 2:
-				c1y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 3
-			case cmd == "Q" && sw == 3:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 4
-			case cmd == "Q" && sw == 4:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "q" && sw == 1:
-				c1x, _ = strconv.ParseFloat(ssSub, 64)
-				c1x = lx + c1x
-				sw = 2
-			case cmd == "q" && sw == 2:
-				c1y, _ = strconv.ParseFloat(ssSub, 64)
-				c1y = ly + c1y
-				sw = 3
-			case cmd == "q" && sw == 3:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 4
-			case cmd == "q" && sw == 4:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
-
-			case cmd == "T" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 2
-			case cmd == "T" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "t" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 2
-			case cmd == "t" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
-
-			case cmd == "A" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 2
-			case cmd == "A" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				sw = 9
-
-			case cmd == "a" && sw == 1:
-				x, _ = strconv.ParseFloat(ssSub, 64)
-				x = lx + x
-				sw = 2
-			case cmd == "a" && sw == 2:
-				y, _ = strconv.ParseFloat(ssSub, 64)
-				y = ly + y
-				sw = 9
+// svgArcCenter internal, converts an SVG endpoint arc from x1,y1 to x2,y2 into its center
+// parameterization (SVG 1.1 F.6.5). Radii which are too small to reach the endpoint are
+// scaled up as required by the spec. Returns false if the arc degrades to a line or nothing
+// ----------------------------------------------------------------------------------------------------------------------
+func svgArcCenter(x1, y1, rx, ry, rot float64, large, sweep bool, x2, y2 float64) (cx, cy, arx, ary, phi, theta1, dtheta float64, ok bool) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	rx = math.Abs(rx)
+	ry = math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return
+	}
+	phi = rot * (math.Pi / 180.0)
+	cosPhi := math.Cos(phi)
+	sinPhi := math.Sin(phi)
 
-			}
-		}
-		if sw == 9 {
-			//fmt.Println(it, "(", cmd, ") lx", lx, "ly", ly, "sizeX", sizeX, "sizeY", sizeY)
-			//fmt.Println("--------------------------------")
-			switch cmd {
-			case "L", "l":
-				p.Line(int((lx+x0)*scale), int((ly+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				lx = x
-				ly = y
-			case "M", "m":
-				lx = x
-				ly = y
-				lcx = x //Set the last Control Point to
-				lcy = y
-			case "H", "h":
-				p.Line(int((lx+x0)*scale), int((ly+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				lx = x
-			case "V", "v":
-				p.Line(int((lx+x0)*scale), int((ly+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				ly = y
-
-			case "C", "c":
-				p.CBezier(int((lx+x0)*scale), int((ly+y0)*scale), int((c1x+x0)*scale), int((c1y+y0)*scale), int((c2x+x0)*scale), int((c2y+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				//p.Line(int(lx), int(ly), int(sizeX), int(sizeY))
-				lx = x
-				ly = y
-				lcx = c2x
-				lcy = c2y
-
-			case "S", "s":
-				c1x = lx - -(lx - lcx)
-				c1y = ly - -(ly - lcy)
-
-				p.CBezier(int((lx+x0)*scale), int((ly+y0)*scale), int((c1x+x0)*scale), int((c1y+y0)*scale), int((c2x+x0)*scale), int((c2y+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				//p.Line(int(lx), int(ly), int(sizeX), int(sizeY))
-				lx = x
-				ly = y
-				lcx = c2x
-				lcy = c2y
-
-			case "Q", "q":
-
-				//c2x = sizeX- -(sizeX-c1x)
-				//c2y = sizeY- -(sizeY-c1y)
-				p.QBezier(int((lx+x0)*scale), int((ly+y0)*scale), int((c1x+x0)*scale), int((c1y+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				//p.Line(int(lx), int(ly), int(sizeX), int(sizeY))
-				lx = x
-				ly = y
-				lcx = c1x
-				lcy = c1y
-
-			case "T", "t":
-				c1x = lx - -(lx - lcx)
-				c1y = ly - -(ly - lcy)
-
-				p.QBezier(int((lx+x0)*scale), int((ly+y0)*scale), int((c1x+x0)*scale), int((c1y+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				//p.Line(int(lx), int(ly), int(sizeX), int(sizeY))
-				lx = x
-				ly = y
-				lcx = c1x
-				lcy = c1y
-
-			case "Z", "z":
-				p.Line(int((lx+x0)*scale), int((ly+y0)*scale), int((x+x0)*scale), int((y+y0)*scale), set)
-				lx = x
-				ly = y
-
-			case "A", "a":
-				//simulate by just setting new ends
-				lx = x
-				ly = y
+	dx := (x1 - x2) / 2
+	dy := (y1 - y2) / 2
+	x1p := cosPhi*dx + sinPhi*dy
+	y1p := -sinPhi*dx + cosPhi*dy
 
-			}
-			sw = 0
-		}
+	//radius correction
+	lambda := (x1p*x1p)/(rx*rx) + (y1p*y1p)/(ry*ry)
+	if lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	den := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	coef := 0.0
+	if den != 0 && num > 0 {
+		coef = math.Sqrt(num / den)
 	}
+	if large == sweep {
+		coef = -coef
+	}
+	cxp := coef * rx * y1p / ry
+	cyp := -coef * ry * x1p / rx
+	cx = cosPhi*cxp - sinPhi*cyp + (x1+x2)/2
+	cy = sinPhi*cxp + cosPhi*cyp + (y1+y2)/2
+
+	theta1 = math.Atan2((y1p-cyp)/ry, (x1p-cxp)/rx)
+	theta2 := math.Atan2((-y1p-cyp)/ry, (-x1p-cxp)/rx)
+	dtheta = theta2 - theta1
+	if sweep && dtheta < 0 {
+		dtheta += 2 * math.Pi
+	}
+	if !sweep && dtheta > 0 {
+		dtheta -= 2 * math.Pi
+	}
+	return cx, cy, rx, ry, phi, theta1, dtheta, true
 }
 
 // QBezier plots a quadratic Bezier in pixelDING
@@ -1990,7 +1884,11 @@ func (p *PixelDING) Rectangle(x0, y0, x1, y1 int, set bool, fill bool) {
 	for i := y0; i < y1; i++ {
 		if fill {
 			for j := x0; j <= x1; j++ {
-				p.setPixel(j, i, set)
+				if i == y0 || j == x0 || j == x1 {
+					p.setPixel(j, i, set)
+				} else {
+					p.fillPixel(j, i, set)
+				}
 			}
 		} else {
 			p.setPixel(x0, i, set)
@@ -2002,22 +1900,48 @@ func (p *PixelDING) Rectangle(x0, y0, x1, y1 int, set bool, fill bool) {
 	}
 }
 
-// floodfill internal
+// floodFill internal, iterative span based flood fill starting at x,y. All connected pixels
+// whose color matches are painted, every pixel is visited only once
 // ----------------------------------------------------------------------------------------------------------------------
-func (p *PixelDING) floodFill(x0, y0 int, prevC, newC bool) {
-	if x0 < 0 || x0 >= p.sizeX || y0 < 0 || y0 >= p.sizeY {
+func (p *PixelDING) floodFill(x0, y0 int, match func(c uint32) bool, paint func(x, y int)) {
+	if !p.check(x0, y0) {
 		return
 	}
-	if prevC != p.getPixel(x0, y0) {
-		return
+	seen := make([]bool, p.sizeX*p.sizeY)
+	inside := func(x, y int) bool {
+		return p.check(x, y) && !seen[y*p.sizeX+x] && match(p.matrix[y][x])
+	}
+	stack := [][2]int{{x0, y0}}
+	for len(stack) > 0 {
+		x, y := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+		if !inside(x, y) {
+			continue
+		}
+		for inside(x-1, y) {
+			x--
+		}
+		lx := x
+		for ; inside(x, y); x++ {
+			seen[y*p.sizeX+x] = true
+			paint(x, y)
+		}
+		rx := x - 1
+		// queue one seed for every run above and below the span
+		for _, ny := range []int{y - 1, y + 1} {
+			run := false
+			for sx := lx; sx <= rx; sx++ {
+				if inside(sx, ny) {
+					if !run {
+						stack = append(stack, [2]int{sx, ny})
+						run = true
+					}
+				} else {
+					run = false
+				}
+			}
+		}
 	}
-	// Replace the color at (sizeX, sizeY)
-	p.setPixel(x0, y0, newC)
-	// Recur for north, east, south and west
-	p.floodFill(x0+1, y0, prevC, newC)
-	p.floodFill(x0-1, y0, prevC, newC)
-	p.floodFill(x0, y0+1, prevC, newC)
-	p.floodFill(x0, y0-1, prevC, newC)
 }
 
 // Fill floodfills the area, starting with the pixel color at x,y
@@ -2028,7 +1952,42 @@ func (p *PixelDING) Fill(x0, y0 int, newC bool) {
 	if prevC == newC {
 		return
 	}
-	p.floodFill(x0, y0, prevC, newC)
+	p.floodFill(x0, y0, func(c uint32) bool {
+		return (c != 0) == prevC
+	}, func(x, y int) {
+		p.fillPixel(x, y, newC)
+	})
+}
+
+// FillC floodfills the area of the exact color found at x,y with the given color.
+// With a tolerance all colors whose red, green and blue parts differ at most by
+// tolerance from the start color are part of the area
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) FillC(x0, y0 int, color uint32, tolerance ...int) {
+	x0, y0 = p.scale(x0, y0)
+	tol := 0
+	if len(tolerance) > 0 {
+		tol = tolerance[0]
+	}
+	prevC := p.getPixelC(x0, y0)
+	if prevC == color && tol == 0 {
+		return
+	}
+	p.floodFill(x0, y0, func(c uint32) bool {
+		return colorDistance(c, prevC) <= tol
+	}, func(x, y int) {
+		p.setPixelC(x, y, color)
+	})
+}
+
+// colorDistance internal, returns the biggest difference of the red, green and blue parts
+// ----------------------------------------------------------------------------------------------------------------------
+func colorDistance(a, b uint32) int {
+	d := 0
+	for shift := 0; shift <= 16; shift += 8 {
+		d = maxInt(d, abs(int((a>>shift)&0xff)-int((b>>shift)&0xff)))
+	}
+	return d
 }
 
 func toRadian(angle int) float64 {
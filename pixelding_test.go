@@ -0,0 +1,140 @@
+package pixelding
+
+import "testing"
+
+// pixels internal test helper, returns the set pixels of the paint area
+func pixels(p *PixelDING) map[[2]int]bool {
+	m := map[[2]int]bool{}
+	for y, row := range p.matrix {
+		for x, v := range row {
+			if v != 0 {
+				m[[2]int{x, y}] = true
+			}
+		}
+	}
+	return m
+}
+
+func TestSVGPathCompactArcFlags(t *testing.T) {
+	compact := New(40, 40)
+	compact.SVGPath(0, 0, "M2 12a10 10 0 1020 0", true)
+	if compact.LastError != nil {
+		t.Fatalf("unexpected error %v", compact.LastError)
+	}
+	spaced := New(40, 40)
+	spaced.SVGPath(0, 0, "M2 12 a10 10 0 1 0 20 0", true)
+
+	got, want := pixels(&compact), pixels(&spaced)
+	if len(got) == 0 {
+		t.Fatal("compact arc flags draw nothing")
+	}
+	if len(got) != len(want) {
+		t.Fatalf("compact arc draws %d pixels, spaced arc %d", len(got), len(want))
+	}
+	for pt := range want {
+		if !got[pt] {
+			t.Fatalf("pixel %v missing", pt)
+		}
+	}
+	//the large arc below the chord reaches down to y=22
+	if !got[[2]int{12, 22}] {
+		t.Error("arc bottom not drawn")
+	}
+}
+
+func TestPictureColorKey(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		keep bool //background at the key pixel stays
+	}{
+		{"older file with color key", `{"mode":3,"colorKey":16711935,"sizeX":2,"sizeY":1,"data":[16711935,255]}`, true},
+		{"black without UseColorKey", `{"mode":3,"colorKey":0,"sizeX":2,"sizeY":1,"data":[0,255]}`, false},
+		{"black with UseColorKey", `{"mode":3,"colorKey":0,"useColorKey":true,"sizeX":2,"sizeY":1,"data":[0,255]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pic PixelPicture
+			if err := unmarshal([]byte(tt.json), &pic); err != nil {
+				t.Fatal(err)
+			}
+			p := New(4, 4)
+			if err := p.ColorMode(ModeTrueColor); err != nil {
+				t.Fatal(err)
+			}
+			p.Color(0x00ff00)
+			p.Rectangle(0, 0, 3, 3, true, true)
+			if err := p.Picture(&pic, 0, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+			if got := p.matrix[0][0] == 0x00ff00; got != tt.keep {
+				t.Errorf("background kept %v, want %v (pixel %06x)", got, tt.keep, p.matrix[0][0])
+			}
+			if p.matrix[0][1] != 0xff {
+				t.Errorf("pixel 1,0 is %06x, want 0000ff", p.matrix[0][1])
+			}
+		})
+	}
+}
+
+func TestFillLargeCanvas(t *testing.T) {
+	//a recursive fill of a few million pixels overflows the stack
+	const size = 2000
+	p := New(size, size)
+	p.Rectangle(100, 100, 199, 199, true, false)
+	p.Fill(0, 0, true)
+	if p.LastError != nil {
+		t.Fatal(p.LastError)
+	}
+	n := 0
+	for _, row := range p.matrix {
+		for _, v := range row {
+			if v != 0 {
+				n++
+			}
+		}
+	}
+	if want := size*size - 98*98; n != want {
+		t.Errorf("%d pixels set, want %d", n, want)
+	}
+	if p.GetPixel(150, 150) || !p.GetPixel(size-1, size-1) {
+		t.Error("fill crossed the border or stopped early")
+	}
+
+	p = New(size, size)
+	_ = p.ColorMode(ModeTrueColor)
+	p.FillC(size/2, size/2, 0x123456)
+	if p.GetPixelC(0, 0) != 0x123456 || p.GetPixelC(size-1, size-1) != 0x123456 {
+		t.Error("FillC stopped early")
+	}
+}
+
+func TestFillCTolerance(t *testing.T) {
+	tests := []struct {
+		name string
+		row  []uint32
+		tol  int
+		want []bool //filled
+	}{
+		{"exact", []uint32{0x808080, 0x808080, 0x808081, 0x808080}, 0, []bool{true, true, false, false}},
+		{"just inside", []uint32{0x808080, 0x8a8080, 0x80768a, 0x808080}, 10, []bool{true, true, true, true}},
+		{"just outside", []uint32{0x808080, 0x80808b, 0x808080}, 10, []bool{true, false, false}},
+		{"from the start color", []uint32{0x808080, 0x868080, 0x8c8080}, 10, []bool{true, true, false}},
+		{"wraps no channel", []uint32{0x0000ff, 0x000100}, 1, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(len(tt.row), 1)
+			_ = p.ColorMode(ModeTrueColor)
+			for x, c := range tt.row {
+				p.PixelC(x, 0, c)
+			}
+			p.FillC(0, 0, 0xff0000, tt.tol)
+			for x, want := range tt.want {
+				if got := p.GetPixelC(x, 0) == 0xff0000; got != want {
+					t.Errorf("pixel %d filled %v, want %v", x, got, want)
+				}
+			}
+		})
+	}
+}
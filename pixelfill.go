@@ -0,0 +1,322 @@
+package pixelding
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+const (
+	FillNonZero = iota
+	FillEvenOdd
+)
+
+const FillRuleError = "fill rule error"
+
+const (
+	FillSolid = iota
+	FillPattern
+	FillLinear
+	FillRadial
+)
+
+// 8x8 fill patterns, the top row is the highest byte, a set bit is painted
+// with the fill color
+const (
+	FillHatchHPattern    = 0xFF000000FF000000 // horizontal lines
+	FillHatchVPattern    = 0x8888888888888888 // vertical lines
+	FillHatchDPattern    = 0x8040201008040201 // falling diagonal lines
+	FillHatchAPattern    = 0x0102040810204080 // rising diagonal lines
+	FillCrossPattern     = 0xFF888888FF888888 // grid
+	FillDiagCrossPattern = 0x8142241818244281 // diagonal grid
+	FillCheckerPattern   = 0xAA55AA55AA55AA55 // 1 pixel checker board
+	FillChecker4Pattern  = 0xF0F0F0F00F0F0F0F // 4 pixel checker board
+	FillDotPattern       = 0x8800220088002200 // sparse dots
+	FillDensePattern     = 0x77DD77DD77DD77DD // dense dots
+)
+
+// PixelFill describes how the inner pixels of a fill operation are painted.
+// Use SolidFill, PatternFill, LinearGradient or RadialGradient to create one
+type PixelFill struct {
+	Style   int
+	Color1  uint32  // solid color, pattern foreground, gradient start color
+	Color2  uint32  // pattern background, gradient end color
+	Pattern uint64  // 8x8 bit pattern
+	Opaque  bool    // pattern: paint the unset bits with Color2
+	X1, Y1  float64 // linear gradient start, radial gradient center
+	X2, Y2  float64 // linear gradient end
+	R       float64 // radial gradient radius
+	HSL     bool    // gradient interpolation in HSL instead of RGB
+}
+
+// FillRule set the winding rule used by Polygon, FillPath and the SVGPath fill
+// need to be one of : FillNonZero (default, as in SVG), FillEvenOdd
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) FillRule(rule int) error {
+	switch rule {
+	case FillNonZero, FillEvenOdd:
+		p.fillrule = rule
+		return nil
+	default:
+		p.LastError = errors.New(FillRuleError)
+		return p.LastError
+	}
+}
+
+// SVGFill enables filling for SVGPath, all sub paths are filled by the current FillRule
+// and the path outline is drawn on top
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) SVGFill(b bool) {
+	p.svgfill = b
+}
+
+// Polygon draws a closed polygon through the given points, filled or unfilled
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Polygon(points [][2]int, set bool, fill bool) {
+	if len(points) == 0 {
+		return
+	}
+	if fill {
+		poly := make([][2]int, len(points))
+		for i, pt := range points {
+			poly[i][0], poly[i][1] = p.scale(pt[0], pt[1])
+		}
+		p.fillPolygons([][][2]int{poly}, set)
+	}
+	for i := range points {
+		j := (i + 1) % len(points)
+		p.Line(points[i][0], points[i][1], points[j][0], points[j][1], set)
+	}
+}
+
+// FillPath fills all sub paths of a PixelPath at x,y by the current FillRule
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) FillPath(path *PixelPath, x0, y0 float64, set bool) {
+	p.fillPolygons(p.pathPolygons(path.Flatten(p.msteps), x0, y0, 1.0), set)
+}
+
+// pathPolygons internal, converts flattened path lines to scaled pixel polygons
+// the same way the path outline is drawn
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) pathPolygons(lines [][][2]float64, x0, y0, scale float64) [][][2]int {
+	polys := make([][][2]int, 0, len(lines))
+	for _, line := range lines {
+		poly := make([][2]int, len(line))
+		for i, pt := range line {
+			poly[i][0], poly[i][1] = p.scale(int((pt[0]+x0)*scale), int((pt[1]+y0)*scale))
+		}
+		polys = append(polys, poly)
+	}
+	return polys
+}
+
+// fillPolygons internal, scanline filler for already scaled polygons, every polygon is implicitly closed.
+// Pixels are sampled at their integer coordinate, the outline itself is drawn by the caller
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) fillPolygons(polys [][][2]int, set bool) {
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	miny, maxy := math.MaxInt32, math.MinInt32
+	for _, poly := range polys {
+		for _, pt := range poly {
+			miny = minInt(miny, pt[1])
+			maxy = maxInt(maxy, pt[1])
+		}
+	}
+	miny = maxInt(miny, 0)
+	maxy = minInt(maxy, p.sizeY-1)
+
+	var xs []crossing
+	for y := miny; y <= maxy; y++ {
+		xs = xs[:0]
+		for _, poly := range polys {
+			for i := range poly {
+				a := poly[i]
+				b := poly[(i+1)%len(poly)]
+				if a[1] == b[1] {
+					continue
+				}
+				dir := 1
+				if a[1] > b[1] {
+					a, b = b, a
+					dir = -1
+				}
+				//half open edges, so vertices are not counted twice
+				if y < a[1] || y >= b[1] {
+					continue
+				}
+				t := float64(y-a[1]) / float64(b[1]-a[1])
+				xs = append(xs, crossing{float64(a[0]) + t*float64(b[0]-a[0]), dir})
+			}
+		}
+		sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+
+		w := 0
+		for i := 0; i < len(xs)-1; i++ {
+			if p.fillrule == FillEvenOdd {
+				w ^= 1
+			} else {
+				w += xs[i].dir
+			}
+			if w != 0 {
+				p.fillSpan(int(math.Ceil(xs[i].x)), int(math.Floor(xs[i+1].x)), y, set)
+			}
+		}
+	}
+}
+
+// fillSpan internal, sets a horizontal run of pixels x1 to x2 on row y
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) fillSpan(x1, x2, y int, set bool) {
+	x1 = maxInt(x1, 0)
+	x2 = minInt(x2, p.sizeX-1)
+	for x := x1; x <= x2; x++ {
+		p.fillPixel(x, y, set)
+	}
+}
+
+// SolidFill returns a fill style painting every pixel with color
+// ----------------------------------------------------------------------------------------------------------------------
+func SolidFill(color uint32) *PixelFill {
+	return &PixelFill{Style: FillSolid, Color1: color}
+}
+
+// PatternFill returns a fill style painting the set bits of the 8x8 pattern with fg.
+// If a background color is given, the unset bits are painted with it, otherwise they stay untouched
+// ----------------------------------------------------------------------------------------------------------------------
+func PatternFill(pattern uint64, fg uint32, bg ...uint32) *PixelFill {
+	f := PixelFill{Style: FillPattern, Pattern: pattern, Color1: fg}
+	if len(bg) > 0 {
+		f.Color2 = bg[0]
+		f.Opaque = true
+	}
+	return &f
+}
+
+// LinearGradient returns a fill style running from c1 at x1,y1 to c2 at x2,y2
+// ----------------------------------------------------------------------------------------------------------------------
+func LinearGradient(x1, y1, x2, y2 float64, c1, c2 uint32, hsl bool) *PixelFill {
+	return &PixelFill{Style: FillLinear, X1: x1, Y1: y1, X2: x2, Y2: y2, Color1: c1, Color2: c2, HSL: hsl}
+}
+
+// RadialGradient returns a fill style running from c1 at the center x,y to c2 at radius r
+// ----------------------------------------------------------------------------------------------------------------------
+func RadialGradient(x0, y0, r float64, c1, c2 uint32, hsl bool) *PixelFill {
+	return &PixelFill{Style: FillRadial, X1: x0, Y1: y0, R: r, Color1: c1, Color2: c2, HSL: hsl}
+}
+
+// FillStyle set the style for all fill operations (Rectangle, Fill, Polygon, FillPath, SVGFill)
+// FillStyle(nil) returns to filling with the current color
+// Gradient coordinates are paint area coordinates and follow the Scale setting
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) FillStyle(style *PixelFill) {
+	p.fillstyle = style
+}
+
+// fillPixel internal, sets a pixel of a fill operation using the current fill style
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) fillPixel(x0, y0 int, set bool) {
+	if !set || p.fillstyle == nil {
+		p.setPixel(x0, y0, set)
+		return
+	}
+	if !p.check(x0, y0) {
+		return
+	}
+	f := p.fillstyle
+	switch f.Style {
+	case FillPattern:
+		if f.Pattern&(1<<(63-((y0&7)*8+(x0&7)))) != 0 {
+			p.matrix[y0][x0] = f.Color1
+		} else if f.Opaque {
+			p.matrix[y0][x0] = f.Color2
+		}
+	case FillLinear:
+		x1, y1, x2, y2 := p.fscale(f.X1), p.fscale(f.Y1), p.fscale(f.X2), p.fscale(f.Y2)
+		dx, dy := x2-x1, y2-y1
+		t := 0.0
+		if d := dx*dx + dy*dy; d > 0 {
+			t = ((float64(x0)-x1)*dx + (float64(y0)-y1)*dy) / d
+		}
+		p.matrix[y0][x0] = gradientColor(f.Color1, f.Color2, t, f.HSL)
+	case FillRadial:
+		t := 0.0
+		if r := p.fscale(f.R); r > 0 {
+			t = math.Hypot(float64(x0)-p.fscale(f.X1), float64(y0)-p.fscale(f.Y1)) / r
+		}
+		p.matrix[y0][x0] = gradientColor(f.Color1, f.Color2, t, f.HSL)
+	default:
+		p.matrix[y0][x0] = f.Color1
+	}
+}
+
+// fscale internal
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) fscale(v float64) float64 {
+	if p.scalef != 0.0 {
+		return v * p.scalef
+	}
+	return v
+}
+
+// gradientColor internal, interpolates between c1 and c2 at t (0..1) in RGB or HSL
+// ----------------------------------------------------------------------------------------------------------------------
+func gradientColor(c1, c2 uint32, t float64, hsl bool) uint32 {
+	t = math.Max(0, math.Min(1, t))
+	if hsl {
+		h1, s1, l1 := rgbToHSL(c1)
+		h2, s2, l2 := rgbToHSL(c2)
+		//take the shorter way around the color wheel
+		if h2-h1 > 0.5 {
+			h1 += 1
+		} else if h1-h2 > 0.5 {
+			h2 += 1
+		}
+		h := h1 + (h2-h1)*t
+		if h >= 1 {
+			h -= 1
+		}
+		return HSL(h, s1+(s2-s1)*t, l1+(l2-l1)*t)
+	}
+	var c uint32
+	for shift := 16; shift >= 0; shift -= 8 {
+		a := float64((c1 >> shift) & 0xff)
+		b := float64((c2 >> shift) & 0xff)
+		c |= uint32(math.Round(a+(b-a)*t)) << shift
+	}
+	return c
+}
+
+// rgbToHSL internal, the counterpart of HSL, h,s,l in the range 0..1
+// ----------------------------------------------------------------------------------------------------------------------
+func rgbToHSL(c uint32) (h, s, l float64) {
+	r := float64((c>>16)&0xff) / 255
+	g := float64((c>>8)&0xff) / 255
+	b := float64(c&0xff) / 255
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+	switch max {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h / 6, s, l
+}
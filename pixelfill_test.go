@@ -0,0 +1,56 @@
+package pixelding
+
+import "testing"
+
+func TestSVGFillOutlineSameParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		outline [][2]int //pixels on the outline
+		inside  [2]int   //pixel inside the filled shape
+		err     bool
+	}{
+		{"implicit lineto", "M0 0 10 0 10 10z", [][2]int{{5, 0}, {10, 5}, {5, 5}}, [2]int{8, 3}, false},
+		{"compact arc flags", "M2 12a10 10 0 1020 0z", [][2]int{{12, 22}, {2, 12}, {22, 12}}, [2]int{12, 17}, false},
+		{"error after valid part", "M0 0 H10 V10 H0 z L", [][2]int{{5, 0}, {10, 5}, {0, 5}}, [2]int{5, 5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outline := New(40, 40)
+			outline.SVGPath(0, 0, tt.path, true)
+			filled := New(40, 40)
+			filled.SVGFill(true)
+			filled.SVGPath(0, 0, tt.path, true)
+			if (filled.LastError != nil) != tt.err {
+				t.Fatalf("LastError %v, want error %v", filled.LastError, tt.err)
+			}
+			got, line := pixels(&filled), pixels(&outline)
+			for _, pt := range tt.outline {
+				if !line[pt] {
+					t.Errorf("outline pixel %v missing", pt)
+				}
+			}
+			for pt := range line {
+				if !got[pt] {
+					t.Errorf("filled path lost outline pixel %v", pt)
+				}
+			}
+			if line[tt.inside] || !got[tt.inside] {
+				t.Errorf("pixel %v: outline %v, filled %v", tt.inside, line[tt.inside], got[tt.inside])
+			}
+		})
+	}
+}
+
+func TestSVGStrictSkipsFillAndOutline(t *testing.T) {
+	p := New(40, 40)
+	p.SVGFill(true)
+	p.SVGStrict(true)
+	p.SVGPath(0, 0, "M0 0 H10 V10 H0 z L", true)
+	if p.LastError == nil {
+		t.Fatal("LastError not set")
+	}
+	if n := len(pixels(&p)); n != 0 {
+		t.Fatalf("strict mode drew %d pixels", n)
+	}
+}
@@ -13,6 +13,8 @@ const (
 	TextFrame   = "+-+|*|+-+"
 )
 const HBar = "\u2588\u258F\u258E\u258D\u258C\u258B\u258A\u2589"
+const VBar = "\u2588\u2581\u2582\u2583\u2584\u2585\u2586\u2587"
+const SparkBlocks = "\u2581\u2582\u2583\u2584\u2585\u2586\u2587\u2588"
 
 const (
 	Dot1x1Pattern   = 0b01010101 // - - - - - - - - - -
@@ -62,59 +64,61 @@ func (p *PixelDING) LoadStdStamp() *PixelStamp {
 func (p *PixelDING) LoadStdFont() *PixelFont {
 	StdFont := PixelFont{
 		Prepared: false,
+		Ascent:   5,
+		Descent:  1,
 		sizex:    0,
 		sizey:    0,
 		Chars: map[int]PixelChar{
-			32: {0, 0, 3, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b000, 0b000, 0b000}},
-			46: {0, 0, 3, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b000, 0b000, 0b010}},
-			44: {0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b000, 0b010, 0b100}},
-			33: {0, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b010, 0b010, 0b000, 0b010}},
-			40: {0, 0, 0, 0, 0, 0, 0, []uint64{0b001, 0b010, 0b010, 0b010, 0b001}},
-			41: {0, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b001, 0b001, 0b001, 0b010}},
-			91: {0, 0, 0, 0, 0, 0, 0, []uint64{0b011, 0b010, 0b010, 0b010, 0b011}},
-			93: {0, 0, 0, 0, 0, 0, 0, []uint64{0b011, 0b001, 0b001, 0b001, 0b011}},
-			42: {0, 0, 0, 0, 0, 0, 0, []uint64{0b00000, 0b00100, 0b11111, 0b01010, 0b00000}},
-			43: {0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b010, 0b111, 0b010, 0b000}},
-			45: {0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b111, 0b000, 0b000}},
-			47: {0, 0, 0, 0, 0, 0, 0, []uint64{0b001, 0b010, 0b010, 0b100, 0b100}},
-			92: {0, 0, 0, 0, 0, 0, 0, []uint64{0b100, 0b010, 0b010, 0b001, 0b001}},
-			61: {0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b111, 0b000, 0b111, 0b000}},
-			65: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b11111, 0b10001, 0b10001}},
-			66: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b11110, 0b10001, 0b11110}},
-			67: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10000, 0b10001, 0b01110}},
-			68: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b10001, 0b10001, 0b11110}},
-			69: {0, 0, 0, 0, 0, 0, 0, []uint64{0b1111, 0b1000, 0b1110, 0b1000, 0b1111}},
-			70: {0, 0, 0, 0, 0, 0, 0, []uint64{0b1111, 0b1000, 0b1110, 0b1000, 0b1000}},
-			71: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10000, 0b10111, 0b10001, 0b01110}},
-			72: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b11111, 0b10001, 0b10001}},
-			73: {0, 0, 0, 0, 0, 0, 0, []uint64{0b111, 0b010, 0b010, 0b010, 0b111}},
-			74: {0, 0, 0, 0, 0, 0, 2, []uint64{0b0001, 0b0001, 0b0001, 0b1001, 0b0110}},
-			75: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b11110, 0b10100, 0b10010, 0b10001}},
-			76: {0, 0, 0, 0, 0, 1, 0, []uint64{0b1000, 0b1000, 0b1000, 0b1000, 0b1111}},
-			77: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b11011, 0b10101, 0b10001, 0b10001}},
-			78: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b11001, 0b10101, 0b10011, 0b10001}},
-			79: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10001, 0b10001, 0b01110}},
-			80: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b11110, 0b10000, 0b10000}},
-			81: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10001, 0b10010, 0b01101}},
-			82: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b11110, 0b10010, 0b10001}},
-			83: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01111, 0b10000, 0b01110, 0b00001, 0b11110}},
-			84: {0, 0, 0, 0, 0, 2, 1, []uint64{0b11111, 0b00100, 0b00100, 0b00100, 0b00100}},
-			85: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b10001, 0b10001, 0b01110}},
-			86: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b10001, 0b01010, 0b00100}},
-			87: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b10101, 0b11011, 0b10001}},
-			88: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b01010, 0b00100, 0b01010, 0b10001}},
-			89: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b01010, 0b00100, 0b00100, 0b00100}},
-			90: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11111, 0b00010, 0b00100, 0b01000, 0b11111}},
-			48: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10101, 0b10001, 0b01110}},
-			49: {0, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b110, 0b010, 0b010, 0b111}},
-			50: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b00001, 0b01110, 0b10000, 0b11111}},
-			51: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b00001, 0b01110, 0b00001, 0b11110}},
-			52: {0, 0, 0, 0, 0, 0, 0, []uint64{0b10010, 0b10010, 0b11111, 0b00010, 0b00010}},
-			53: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11111, 0b10000, 0b11110, 0b00001, 0b11110}},
-			54: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10000, 0b11110, 0b10001, 0b01110}},
-			55: {0, 0, 0, 0, 0, 0, 0, []uint64{0b11111, 0b00001, 0b00010, 0b00100, 0b01000}},
-			56: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b01110, 0b10001, 0b01110}},
-			57: {0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b01111, 0b00001, 0b01110}},
+			32: {0, 0, 3, 0, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b000, 0b000, 0b000}},
+			46: {0, 0, 3, 0, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b000, 0b000, 0b010}},
+			44: {0, 1, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b100}},
+			33: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b010, 0b010, 0b000, 0b010}},
+			40: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b001, 0b010, 0b010, 0b010, 0b001}},
+			41: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b001, 0b001, 0b001, 0b010}},
+			91: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b011, 0b010, 0b010, 0b010, 0b011}},
+			93: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b011, 0b001, 0b001, 0b001, 0b011}},
+			42: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b00000, 0b00100, 0b11111, 0b01010, 0b00000}},
+			43: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b010, 0b111, 0b010, 0b000}},
+			45: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b000, 0b111, 0b000, 0b000}},
+			47: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b001, 0b010, 0b010, 0b100, 0b100}},
+			92: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b100, 0b010, 0b010, 0b001, 0b001}},
+			61: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b000, 0b111, 0b000, 0b111, 0b000}},
+			65: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b11111, 0b10001, 0b10001}},
+			66: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b11110, 0b10001, 0b11110}},
+			67: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10000, 0b10001, 0b01110}},
+			68: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b10001, 0b10001, 0b11110}},
+			69: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b1111, 0b1000, 0b1110, 0b1000, 0b1111}},
+			70: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b1111, 0b1000, 0b1110, 0b1000, 0b1000}},
+			71: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10000, 0b10111, 0b10001, 0b01110}},
+			72: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b11111, 0b10001, 0b10001}},
+			73: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b111, 0b010, 0b010, 0b010, 0b111}},
+			74: {0, 0, 0, 0, 0, 0, 2, 0, []uint64{0b0001, 0b0001, 0b0001, 0b1001, 0b0110}},
+			75: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b11110, 0b10100, 0b10010, 0b10001}},
+			76: {0, 0, 0, 0, 0, 1, 0, 0, []uint64{0b1000, 0b1000, 0b1000, 0b1000, 0b1111}},
+			77: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b11011, 0b10101, 0b10001, 0b10001}},
+			78: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b11001, 0b10101, 0b10011, 0b10001}},
+			79: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10001, 0b10001, 0b01110}},
+			80: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b11110, 0b10000, 0b10000}},
+			81: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10001, 0b10010, 0b01101}},
+			82: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b10001, 0b11110, 0b10010, 0b10001}},
+			83: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01111, 0b10000, 0b01110, 0b00001, 0b11110}},
+			84: {0, 0, 0, 0, 0, 2, 1, 0, []uint64{0b11111, 0b00100, 0b00100, 0b00100, 0b00100}},
+			85: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b10001, 0b10001, 0b01110}},
+			86: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b10001, 0b01010, 0b00100}},
+			87: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b10001, 0b10101, 0b11011, 0b10001}},
+			88: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b01010, 0b00100, 0b01010, 0b10001}},
+			89: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10001, 0b01010, 0b00100, 0b00100, 0b00100}},
+			90: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11111, 0b00010, 0b00100, 0b01000, 0b11111}},
+			48: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b10101, 0b10001, 0b01110}},
+			49: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b010, 0b110, 0b010, 0b010, 0b111}},
+			50: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b00001, 0b01110, 0b10000, 0b11111}},
+			51: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11110, 0b00001, 0b01110, 0b00001, 0b11110}},
+			52: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b10010, 0b10010, 0b11111, 0b00010, 0b00010}},
+			53: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11111, 0b10000, 0b11110, 0b00001, 0b11110}},
+			54: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10000, 0b11110, 0b10001, 0b01110}},
+			55: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b11111, 0b00001, 0b00010, 0b00100, 0b01000}},
+			56: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b01110, 0b10001, 0b01110}},
+			57: {0, 0, 0, 0, 0, 0, 0, 0, []uint64{0b01110, 0b10001, 0b01111, 0b00001, 0b01110}},
 		},
 	}
 	f := p.PrepareFont(StdFont)
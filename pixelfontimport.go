@@ -0,0 +1,282 @@
+package pixelding
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const FontFormatError = "font format error"
+
+// LoadBDF loads an X11 BDF bitmap font into a prepared pixelDING font object
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) LoadBDF(name string) *PixelFont {
+	file, err := os.Open(name)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	defer file.Close()
+	font, err := ParseBDF(file)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	return font
+}
+
+// LoadPSF loads a Linux console PSF1 or PSF2 font into a prepared pixelDING font object, gzip
+// compressed files (.psf.gz) are unpacked
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) LoadPSF(name string) *PixelFont {
+	buf, err := os.ReadFile(name)
+	if err == nil && len(buf) > 2 && buf[0] == 0x1f && buf[1] == 0x8b {
+		var z *gzip.Reader
+		if z, err = gzip.NewReader(bytes.NewReader(buf)); err == nil {
+			buf, err = io.ReadAll(z)
+		}
+	}
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	font, err := ParsePSF(buf)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	return font
+}
+
+// ParseBDF reads a BDF font. The font ascent and descent are the Ascent and Descent of the font, the glyphs
+// stand on the baseline moved by their BBX offsets, the advance width is the DWIDTH of the glyph
+// ----------------------------------------------------------------------------------------------------------------------
+func ParseBDF(r io.Reader) (*PixelFont, error) {
+	font := &PixelFont{Chars: map[int]PixelChar{}}
+	ascent, descent := 0, 0
+	bbox := []int{0, 0, 0, 0}
+	code, dwidth := -1, 0
+	var bbx []int
+	var rows []string
+	inBitmap := false
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if inBitmap {
+			if fields[0] != "ENDCHAR" {
+				rows = append(rows, fields[0])
+				continue
+			}
+			inBitmap = false
+			if code >= 0 && len(bbx) == 4 {
+				ch, err := bdfChar(rows, bbx, dwidth)
+				if err != nil {
+					return nil, err
+				}
+				font.Chars[code] = ch
+			}
+			continue
+		}
+		args := bdfInts(fields[1:])
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if len(args) == 4 {
+				bbox = args
+			}
+		case "FONT_ASCENT":
+			if len(args) == 1 {
+				ascent = args[0]
+			}
+		case "FONT_DESCENT":
+			if len(args) == 1 {
+				descent = args[0]
+			}
+		case "STARTCHAR":
+			code, dwidth, bbx, rows = -1, 0, nil, nil
+		case "ENCODING":
+			if len(args) > 0 {
+				code = args[0]
+			}
+		case "DWIDTH":
+			if len(args) > 0 {
+				dwidth = args[0]
+			}
+		case "BBX":
+			bbx = args
+		case "BITMAP":
+			inBitmap = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(font.Chars) == 0 {
+		return nil, errors.New(FontFormatError)
+	}
+	if ascent == 0 && descent == 0 {
+		ascent, descent = bbox[1]+bbox[3], -bbox[3]
+	}
+	font.Ascent, font.Descent = maxInt(ascent, 1), descent
+	font.Prepared = true
+	return font, nil
+}
+
+// bdfInts internal, converts the numeric fields of a BDF line
+// ----------------------------------------------------------------------------------------------------------------------
+func bdfInts(fields []string) []int {
+	var v []int
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return v
+		}
+		v = append(v, n)
+	}
+	return v
+}
+
+// bdfChar internal, builds a prepared char from the hex rows and the BBX w, h, xoff, yoff of a BDF glyph
+// ----------------------------------------------------------------------------------------------------------------------
+func bdfChar(rows []string, bbx []int, dwidth int) (PixelChar, error) {
+	w, h, xoff, yoff := bbx[0], bbx[1], bbx[2], bbx[3]
+	//BDF offsets count upwards, OffsetY downwards
+	ch := PixelChar{OffsetX: xoff, OffsetY: -yoff, SizeX: w, SizeY: h, Advance: dwidth}
+	if w > 64 || w < 0 || h < 0 {
+		return ch, errors.New(FontFormatError)
+	}
+	data := make([]uint64, h)
+	for i := 0; i < h && i < len(rows); i++ {
+		v, err := strconv.ParseUint(rows[i], 16, 64)
+		if err != nil || len(rows[i]) > 16 {
+			return ch, errors.New(FontFormatError)
+		}
+		//the hex row is padded to full bytes, the leftmost pixel is the highest bit
+		data[i] = v >> uint(maxInt(len(rows[i])*4-w, 0))
+	}
+	ch.Data, ch.Len = leftBound(data, maxInt(w, 1))
+	return ch, nil
+}
+
+// ParsePSF reads a PSF1 or PSF2 console font. Glyphs with a unicode table are mapped to all their
+// code points, otherwise glyph n is code point n
+// ----------------------------------------------------------------------------------------------------------------------
+func ParsePSF(buf []byte) (*PixelFont, error) {
+	var width, height, count, size, start int
+	psf2, unicode := false, false
+	switch {
+	case len(buf) >= 4 && buf[0] == 0x36 && buf[1] == 0x04:
+		mode := buf[2]
+		width, height, size, start = 8, int(buf[3]), int(buf[3]), 4
+		count = 256
+		if mode&0x01 != 0 {
+			count = 512
+		}
+		unicode = mode&0x06 != 0
+	case len(buf) >= 32 && binary.LittleEndian.Uint32(buf) == 0x864ab572:
+		psf2 = true
+		hdr := make([]uint32, 8)
+		for i := range hdr {
+			hdr[i] = binary.LittleEndian.Uint32(buf[i*4:])
+		}
+		start, count, size = int(hdr[2]), int(hdr[4]), int(hdr[5])
+		height, width = int(hdr[6]), int(hdr[7])
+		if start < 32 || start > len(buf) {
+			return nil, errors.New(FontFormatError)
+		}
+		unicode = hdr[3]&0x01 != 0
+	default:
+		return nil, errors.New(FontFormatError)
+	}
+	//the header values are checked by division, a product of them may overflow
+	stride := (width + 7) / 8
+	if width <= 0 || width > 64 || height <= 0 || size <= 0 || height > size/stride || count <= 0 ||
+		count > (len(buf)-start)/size {
+		return nil, errors.New(FontFormatError)
+	}
+	var table []byte
+	if unicode {
+		table = buf[start+count*size:]
+	}
+
+	glyphs := make([]PixelChar, count)
+	for g := range glyphs {
+		data := make([]uint64, height)
+		for y := 0; y < height; y++ {
+			var v uint64
+			for i := 0; i < stride; i++ {
+				v = v<<8 | uint64(buf[start+g*size+y*stride+i])
+			}
+			data[y] = v >> uint(stride*8-width)
+		}
+		ch := PixelChar{SizeX: width, SizeY: height, Advance: width}
+		ch.Data, ch.Len = leftBound(data, width)
+		glyphs[g] = ch
+	}
+
+	font := &PixelFont{Chars: map[int]PixelChar{}, Ascent: height}
+	if table == nil {
+		for g, ch := range glyphs {
+			font.Chars[g] = ch
+		}
+	} else if psf2 {
+		psf2Table(font, glyphs, table)
+	} else {
+		psf1Table(font, glyphs, table)
+	}
+	font.Prepared = true
+	return font, nil
+}
+
+// psf1Table internal, maps the glyphs by a PSF1 unicode table of 16 bit values, 0xFFFF ends a glyph,
+// 0xFFFE starts the sequences which are skipped
+// ----------------------------------------------------------------------------------------------------------------------
+func psf1Table(font *PixelFont, glyphs []PixelChar, table []byte) {
+	g, seq := 0, false
+	for i := 0; i+1 < len(table) && g < len(glyphs); i += 2 {
+		switch v := binary.LittleEndian.Uint16(table[i:]); v {
+		case 0xFFFF:
+			g, seq = g+1, false
+		case 0xFFFE:
+			seq = true
+		default:
+			if !seq {
+				font.Chars[int(v)] = glyphs[g]
+			}
+		}
+	}
+}
+
+// psf2Table internal, maps the glyphs by a PSF2 unicode table of UTF-8 values, 0xFF ends a glyph,
+// 0xFE starts the sequences which are skipped
+// ----------------------------------------------------------------------------------------------------------------------
+func psf2Table(font *PixelFont, glyphs []PixelChar, table []byte) {
+	for g := 0; g < len(glyphs) && len(table) > 0; g++ {
+		entry := table
+		if end := bytes.IndexByte(table, 0xFF); end >= 0 {
+			entry, table = table[:end], table[end+1:]
+		} else {
+			table = nil
+		}
+		if seq := bytes.IndexByte(entry, 0xFE); seq >= 0 {
+			entry = entry[:seq]
+		}
+		for len(entry) > 0 {
+			r, n := utf8.DecodeRune(entry)
+			if r != utf8.RuneError {
+				font.Chars[int(r)] = glyphs[g]
+			}
+			entry = entry[n:]
+		}
+	}
+}
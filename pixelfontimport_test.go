@@ -0,0 +1,193 @@
+package pixelding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed
+FONTBOUNDINGBOX 6 8 0 -2
+STARTPROPERTIES 2
+FONT_ASCENT 6
+FONT_DESCENT 2
+ENDPROPERTIES
+CHARS 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 6 0
+BBX 5 6 0 0
+BITMAP
+20
+50
+88
+F8
+88
+88
+ENDCHAR
+STARTCHAR j
+ENCODING 106
+DWIDTH 4 0
+BBX 3 8 1 -2
+BITMAP
+20
+00
+20
+20
+20
+20
+A0
+40
+ENDCHAR
+ENDFONT
+`
+
+func TestParseBDF(t *testing.T) {
+	font, err := ParseBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.Ascent != 6 || font.Descent != 2 || len(font.Chars) != 2 || !font.Prepared {
+		t.Fatalf("ascent %d descent %d chars %d", font.Ascent, font.Descent, len(font.Chars))
+	}
+	a, j := font.Chars['A'], font.Chars['j']
+	if a.SizeX != 5 || a.SizeY != 6 || a.Advance != 6 || a.OffsetX != 0 || a.OffsetY != 0 ||
+		a.Data[3] != 0x1F<<59 || a.Data[0] != 0x04<<59 {
+		t.Errorf("char A: %+v", a)
+	}
+	if j.SizeX != 3 || j.Advance != 4 || j.OffsetX != 1 || j.OffsetY != 2 || j.Data[6] != 5<<61 {
+		t.Errorf("char j: %+v", j)
+	}
+}
+
+func TestParseBDFCorrupt(t *testing.T) {
+	tests := map[string]string{
+		"empty":    "",
+		"no chars": "STARTFONT 2.1\nFONTBOUNDINGBOX 6 8 0 -2\nENDFONT\n",
+		"bad hex":  strings.Replace(testBDF, "F8", "XY", 1),
+		"too wide": strings.Replace(testBDF, "BBX 5 6 0 0", "BBX 65 6 0 0", 1),
+		"negative": strings.Replace(testBDF, "BBX 5 6 0 0", "BBX 5 -6 0 0", 1),
+	}
+	for name, src := range tests {
+		if font, err := ParseBDF(strings.NewReader(src)); err == nil || err.Error() != FontFormatError || font != nil {
+			t.Errorf("%s: font %v err %v", name, font != nil, err)
+		}
+	}
+}
+
+// testPSF2 internal test helper, a PSF2 font of count glyphs width x height, glyph n has row y set to n+y
+func testPSF2(count, width, height int, table []byte) []byte {
+	stride := (width + 7) / 8
+	var b bytes.Buffer
+	flags := uint32(0)
+	if table != nil {
+		flags = 1
+	}
+	_ = binary.Write(&b, binary.LittleEndian, []uint32{0x864ab572, 0, 32, flags, uint32(count),
+		uint32(stride * height), uint32(height), uint32(width)})
+	for g := 0; g < count; g++ {
+		for y := 0; y < height; y++ {
+			row := make([]byte, stride)
+			row[0] = byte(g + y)
+			b.Write(row)
+		}
+	}
+	b.Write(table)
+	return b.Bytes()
+}
+
+func TestParsePSF(t *testing.T) {
+	//PSF1, 256 glyphs of 8x4, glyph n has every row n, unicode table maps glyph 0 to 'a' and 'b', glyph 1 to 'c'
+	psf1 := []byte{0x36, 0x04, 0x02, 4}
+	for g := 0; g < 256; g++ {
+		psf1 = append(psf1, byte(g), byte(g), byte(g), byte(g))
+	}
+	psf1 = append(psf1, 'a', 0, 'b', 0, 0xFE, 0xFF, 'x', 0, 0xFF, 0xFF, 'c', 0, 0xFF, 0xFF)
+	font, err := ParsePSF(psf1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(font.Chars) != 3 || font.Ascent != 4 {
+		t.Fatalf("PSF1: %d chars, ascent %d", len(font.Chars), font.Ascent)
+	}
+	if c := font.Chars['c']; c.SizeX != 8 || c.SizeY != 4 || c.Advance != 8 || c.Data[0] != 1<<56 {
+		t.Errorf("PSF1 char c: %+v", c)
+	}
+	if _, ok := font.Chars['x']; ok {
+		t.Error("PSF1 sequence mapped")
+	}
+
+	//PSF2 without table, glyph n is char n, 10 pixel wide rows take two bytes
+	font, err = ParsePSF(testPSF2(3, 10, 2, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(font.Chars) != 3 || font.Chars[2].SizeX != 10 || font.Chars[2].Data[1] != 3<<56 {
+		t.Errorf("PSF2: %d chars, char 2 %+v", len(font.Chars), font.Chars[2])
+	}
+
+	//PSF2 with a UTF-8 table
+	font, err = ParsePSF(testPSF2(2, 8, 2, []byte("ä€\xFE\x41\xFFz\xFF")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(font.Chars) != 3 || font.Chars['€'].Data[0] != 0 || font.Chars['z'].Data[0] != 1<<56 {
+		t.Errorf("PSF2 table: %d chars", len(font.Chars))
+	}
+}
+
+func TestParsePSFCorrupt(t *testing.T) {
+	huge := testPSF2(1, 8, 1, nil)
+	binary.LittleEndian.PutUint32(huge[16:], 0xFFFFFFFF) //count
+	binary.LittleEndian.PutUint32(huge[20:], 0xFFFFFFFF) //size
+	huge = append(huge, make([]byte, 64-len(huge))...)
+	hugeSize := testPSF2(1, 8, 1, []byte{0xFF})
+	binary.LittleEndian.PutUint32(hugeSize[20:], 0x7FFFFFFF)
+	hugeHeight := testPSF2(1, 8, 1, nil)
+	binary.LittleEndian.PutUint32(hugeHeight[24:], 0xFFFFFFFF)
+	start := testPSF2(1, 8, 1, nil)
+	binary.LittleEndian.PutUint32(start[8:], 1000)
+	valid := testPSF2(4, 8, 4, nil)
+
+	tests := map[string][]byte{
+		"empty":       nil,
+		"magic":       []byte("not a font at all, really not a font"),
+		"psf1 short":  {0x36, 0x04, 0x00, 8, 1, 2, 3},
+		"psf1 height": append([]byte{0x36, 0x04, 0x00, 0}, make([]byte, 64)...),
+		"psf2 header": valid[:20],
+		"truncated":   valid[:len(valid)-1],
+		"count size":  huge,
+		"size":        hugeSize,
+		"height":      hugeHeight,
+		"start":       start,
+		"width":       testPSF2(1, 65, 1, nil),
+	}
+	for name, buf := range tests {
+		if font, err := ParsePSF(buf); err == nil || err.Error() != FontFormatError || font != nil {
+			t.Errorf("%s: font %v err %v", name, font != nil, err)
+		}
+	}
+}
+
+func TestLoadPSFGzip(t *testing.T) {
+	var z bytes.Buffer
+	w := gzip.NewWriter(&z)
+	_, _ = w.Write(testPSF2(2, 8, 2, nil))
+	_ = w.Close()
+	name := filepath.Join(t.TempDir(), "test.psf.gz")
+	if err := os.WriteFile(name, z.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := New(8, 8)
+	if font := p.LoadPSF(name); font == nil || len(font.Chars) != 2 {
+		t.Errorf("font %v, LastError %v", font, p.LastError)
+	}
+	if font := p.LoadPSF(name + ".missing"); font != nil || p.LastError == nil {
+		t.Error("missing file loaded")
+	}
+}
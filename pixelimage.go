@@ -0,0 +1,184 @@
+package pixelding
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+)
+
+// ansiColors the RGB values of the 16 standard console colors
+var ansiColors = [16]uint32{
+	0x000000, 0x800000, 0x008000, 0x808000, 0x000080, 0x800080, 0x008080, 0xC0C0C0,
+	0x808080, 0xFF0000, 0x00FF00, 0xFFFF00, 0x0000FF, 0xFF00FF, 0x00FFFF, 0xFFFFFF,
+}
+
+// ImageColors set the RGB colors for set and unset pixels of ModeNoColor in the image outputs,
+// default is white on black
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) ImageColors(set, unset uint32) {
+	p.imgset = set & 0xffffff
+	p.imgunset = unset & 0xffffff
+}
+
+// ColorModel returns the color model of the pixelDING image, pixelDING implements image.Image
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds returns the dimension of the pixelDING image
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Bounds() image.Rectangle {
+	return image.Rect(0, 0, p.sizeX, p.sizeY)
+}
+
+// At returns the color of the pixel at x,y as RGB color
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) At(x, y int) color.Color {
+	var c uint32
+	if image.Pt(x, y).In(p.Bounds()) {
+		c = p.matrix[y][x]
+	}
+	c = p.rgbColor(c)
+	return color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xff}
+}
+
+// Image returns a copy of the rectangle x1,y1 to x2,y2 as RGBA image, without parameters the full dimension
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Image(xyxy ...int) *image.RGBA {
+	if len(xyxy) > 3 {
+		return p.imageXY(xyxy[0], xyxy[1], xyxy[2], xyxy[3])
+	}
+	return p.imageXY(0, 0, p.sizeX, p.sizeY)
+}
+
+// SavePNG saves the pixelDING drawing as PNG file
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) SavePNG(name string, permissions os.FileMode) error {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, p.Image())
+	if err == nil {
+		err = os.WriteFile(name, buf.Bytes(), permissions)
+	}
+	if err != nil {
+		p.LastError = err
+		return err
+	}
+	return nil
+}
+
+// SaveGIF saves the pixelDING drawing as GIF file, more than 256 colors are reduced by a median cut
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) SaveGIF(name string, permissions os.FileMode) error {
+	var buf bytes.Buffer
+	err := gif.Encode(&buf, p.paletted(), nil)
+	if err == nil {
+		err = os.WriteFile(name, buf.Bytes(), permissions)
+	}
+	if err != nil {
+		p.LastError = err
+		return err
+	}
+	return nil
+}
+
+// paletted internal, converts the pixelDING drawing into a paletted image with at most 256 colors
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) paletted() *image.Paletted {
+	hist := map[uint32]int{}
+	for y := 0; y < p.sizeY; y++ {
+		for x := 0; x < p.sizeX; x++ {
+			hist[p.rgbColor(p.matrix[y][x])]++
+		}
+	}
+	colors := quantizeColors(hist, 256)
+	palette := make(color.Palette, len(colors))
+	for i, c := range colors {
+		palette[i] = color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xff}
+	}
+	index := map[uint32]uint8{}
+	for c := range hist {
+		index[c] = uint8(nearestColor(colors, c))
+	}
+	img := image.NewPaletted(p.Bounds(), palette)
+	for y := 0; y < p.sizeY; y++ {
+		for x := 0; x < p.sizeX; x++ {
+			img.SetColorIndex(x, y, index[p.rgbColor(p.matrix[y][x])])
+		}
+	}
+	return img
+}
+
+// paletteColor internal, returns the RGB value of a 256 color palette index
+// ----------------------------------------------------------------------------------------------------------------------
+func paletteColor(i uint32) uint32 {
+	i &= 0xff
+	switch {
+	case i < 16:
+		return ansiColors[i]
+	case i < 232:
+		i -= 16
+		level := func(v uint32) uint32 {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return level(i/36)<<16 | level((i/6)%6)<<8 | level(i%6)
+	default:
+		g := 8 + (i-232)*10
+		return g<<16 | g<<8 | g
+	}
+}
+
+// rgbColor internal, converts a matrix value into an RGB color depending on the color mode.
+// In ModeNoColor set and unset pixels get the ImageColors, Invert swaps them
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) rgbColor(c uint32) uint32 {
+	switch p.colorrender {
+	case ModeTrueColor:
+		return c & 0xffffff
+	case ModePaletteColor:
+		return paletteColor(c)
+	case Mode16Color:
+		switch {
+		case c >= 30 && c <= 37:
+			return ansiColors[c-30]
+		case c >= 90 && c <= 97:
+			return ansiColors[c-90+8]
+		}
+		return ansiColors[0]
+	}
+	if (c != 0) != p.invert {
+		return p.imgset
+	}
+	return p.imgunset
+}
+
+// renderRegion internal, returns the area rendered by the image renderers, the clipping area if
+// clipping is enabled, otherwise the full dimension
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) renderRegion() (int, int, int, int) {
+	if p.clipping {
+		return p.clipsx, p.clipsy, p.clipex + 1, p.clipey + 1
+	}
+	return 0, 0, p.sizeX, p.sizeY
+}
+
+// imageXY internal, converts the rectangle x1,y1 to x2,y2 into an RGBA image
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) imageXY(x1, y1, x2, y2 int) *image.RGBA {
+	x1, y1 = maxInt(x1, 0), maxInt(y1, 0)
+	x2, y2 = maxInt(minInt(x2, p.sizeX), x1), maxInt(minInt(y2, p.sizeY), y1)
+	img := image.NewRGBA(image.Rect(0, 0, x2-x1, y2-y1))
+	for y := y1; y < y2; y++ {
+		for x := x1; x < x2; x++ {
+			c := p.rgbColor(p.matrix[y][x])
+			img.SetRGBA(x-x1, y-y1, color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xff})
+		}
+	}
+	return img
+}
@@ -0,0 +1,143 @@
+package pixelding
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// rgb internal test helper, the RGB value of a color
+func rgb(c color.Color) uint32 {
+	r, g, b, _ := c.RGBA()
+	return (r>>8)<<16 | (g>>8)<<8 | b>>8
+}
+
+func TestImageAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  int
+		value uint32
+		want  uint32
+	}{
+		{"true color", ModeTrueColor, 0x123456, 0x123456},
+		{"palette ansi", ModePaletteColor, 9, 0xff0000},
+		{"palette cube", ModePaletteColor, 16 + 36*5 + 6*2 + 1, 0xff875f},
+		{"palette gray", ModePaletteColor, 232, 0x080808},
+		{"16 colors", Mode16Color, 32, 0x008000},
+		{"16 colors bright", Mode16Color, 93, 0xffff00},
+		{"no color", ModeNoColor, 1, 0xffffff},
+	}
+	for _, tt := range tests {
+		p := New(3, 2)
+		_ = p.ColorMode(tt.mode)
+		p.matrix[1][2] = tt.value
+		if got := rgb(p.At(2, 1)); got != tt.want {
+			t.Errorf("%s: At is %06x, want %06x", tt.name, got, tt.want)
+		}
+		if _, _, _, a := p.At(2, 1).RGBA(); a != 0xffff {
+			t.Errorf("%s: alpha %x", tt.name, a)
+		}
+	}
+
+	p := New(3, 2)
+	if p.ColorModel() != color.RGBAModel || p.Bounds() != image.Rect(0, 0, 3, 2) {
+		t.Errorf("color model %v bounds %v", p.ColorModel(), p.Bounds())
+	}
+	//outside the bounds is an unset pixel, ModeNoColor follows ImageColors and Invert
+	p.ImageColors(0x00ff00, 0x000080)
+	p.Pixel(0, 0, true)
+	if rgb(p.At(0, 0)) != 0x00ff00 || rgb(p.At(1, 0)) != 0x000080 || rgb(p.At(-1, 5)) != 0x000080 {
+		t.Errorf("image colors %06x %06x %06x", rgb(p.At(0, 0)), rgb(p.At(1, 0)), rgb(p.At(-1, 5)))
+	}
+	p.Invert(true)
+	if rgb(p.At(0, 0)) != 0x000080 || rgb(p.At(1, 0)) != 0x00ff00 {
+		t.Error("Invert does not swap the image colors")
+	}
+}
+
+func TestImageRect(t *testing.T) {
+	p := New(4, 4)
+	_ = p.ColorMode(ModeTrueColor)
+	p.PixelC(2, 1, 0xabcdef)
+	img := p.Image(1, 1, 3, 10) //cut at the bottom
+	if img.Bounds() != image.Rect(0, 0, 2, 3) {
+		t.Fatalf("bounds %v", img.Bounds())
+	}
+	if rgb(img.At(1, 0)) != 0xabcdef || rgb(img.At(0, 0)) != 0 {
+		t.Error("sub image pixels")
+	}
+	if p.Image().Bounds() != p.Bounds() {
+		t.Error("full image bounds")
+	}
+}
+
+func TestSavePNGGIF(t *testing.T) {
+	dir := t.TempDir()
+	p := New(20, 20)
+	_ = p.ColorMode(ModeTrueColor)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			p.PixelC(x, y, uint32(x*12)<<16|uint32(y*12)<<8|0x40) //400 colors
+		}
+	}
+	name := filepath.Join(dir, "a.png")
+	if err := p.SavePNG(name, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.Open(name)
+	img, err := png.Decode(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pt := range [][2]int{{0, 0}, {19, 0}, {7, 13}} {
+		if got := rgb(img.At(pt[0], pt[1])); got != p.matrix[pt[1]][pt[0]] {
+			t.Errorf("PNG pixel %v is %06x, want %06x", pt, got, p.matrix[pt[1]][pt[0]])
+		}
+	}
+
+	name = filepath.Join(dir, "a.gif")
+	if err := p.SaveGIF(name, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = os.Open(name)
+	g, err := gif.Decode(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pal, ok := g.ColorModel().(color.Palette)
+	if !ok || len(pal) > 256 || g.Bounds() != p.Bounds() {
+		t.Fatalf("GIF palette %d colors, bounds %v", len(pal), g.Bounds())
+	}
+	//the median cut keeps every color close
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			if d := colorDistance(rgb(g.At(x, y)), p.matrix[y][x]); d > 16 {
+				t.Fatalf("GIF pixel %d,%d is %06x, want about %06x", x, y, rgb(g.At(x, y)), p.matrix[y][x])
+			}
+		}
+	}
+
+	//few colors are kept exactly
+	q := New(3, 1)
+	_ = q.ColorMode(ModePaletteColor)
+	q.PixelC(1, 0, 196)
+	name = filepath.Join(dir, "b.gif")
+	if err := q.SaveGIF(name, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = os.Open(name)
+	g, err = gif.Decode(f)
+	f.Close()
+	if err != nil || rgb(g.At(1, 0)) != 0xff0000 || rgb(g.At(0, 0)) != 0 {
+		t.Errorf("GIF palette picture: %v", err)
+	}
+	if err := q.SavePNG(filepath.Join(dir, "missing", "c.png"), 0644); err == nil || q.LastError == nil {
+		t.Error("SavePNG into a missing directory")
+	}
+}
@@ -0,0 +1,79 @@
+package pixelding
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"strings"
+)
+
+// KittyChunk the maximum payload size of one kitty graphics escape sequence
+const KittyChunk = 4096
+
+// RenderKitty renders the pixelDING object as PNG image for the kitty graphics protocol, with clipping
+// enabled only the clipping area is rendered. The text overlay is not part of the image
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderKitty() []string {
+	return p.RenderKittyXY(p.renderRegion())
+}
+
+// RenderKittyXY renders a given rectangle from pixelDING as PNG image for the kitty graphics protocol,
+// given by x1,y1 to x2,y2. The image data is split into chunks of KittyChunk bytes
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderKittyXY(x1, y1, x2, y2 int) []string {
+	raw, err := p.pngXY(x1, y1, x2, y2)
+	if err != nil {
+		p.buffer = []string{}
+		return p.buffer
+	}
+	data := base64.StdEncoding.EncodeToString(raw)
+	var sb strings.Builder
+	for i := 0; i < len(data) || i == 0; i += KittyChunk {
+		more := 0
+		if i+KittyChunk < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			sb.WriteString(fmt.Sprint("\033_Gf=100,a=T,m=", more, ";"))
+		} else {
+			sb.WriteString(fmt.Sprint("\033_Gm=", more, ";"))
+		}
+		sb.WriteString(data[i:minInt(i+KittyChunk, len(data))])
+		sb.WriteString("\033\\")
+	}
+	p.buffer = []string{sb.String()}
+	return p.buffer
+}
+
+// RenderITerm renders the pixelDING object as PNG image for the iTerm2 inline image protocol (OSC 1337),
+// with clipping enabled only the clipping area is rendered. The text overlay is not part of the image
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderITerm() []string {
+	return p.RenderITermXY(p.renderRegion())
+}
+
+// RenderITermXY renders a given rectangle from pixelDING as PNG image for the iTerm2 inline image protocol,
+// given by x1,y1 to x2,y2
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderITermXY(x1, y1, x2, y2 int) []string {
+	raw, err := p.pngXY(x1, y1, x2, y2)
+	if err != nil {
+		p.buffer = []string{}
+		return p.buffer
+	}
+	p.buffer = []string{fmt.Sprint("\033]1337;File=inline=1;size=", len(raw), ";preserveAspectRatio=1:",
+		base64.StdEncoding.EncodeToString(raw), "\a")}
+	return p.buffer
+}
+
+// pngXY internal, returns the rectangle x1,y1 to x2,y2 PNG encoded
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) pngXY(x1, y1, x2, y2 int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, p.imageXY(x1, y1, x2, y2)); err != nil {
+		p.LastError = err
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,147 @@
+package pixelding
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// decodePNG internal test helper, decodes base64 PNG data
+func decodePNG(t *testing.T, data string) image.Image {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// kittyChunks internal test helper, splits kitty graphics output into the control data and payloads
+func kittyChunks(t *testing.T, s string) ([]string, []string) {
+	t.Helper()
+	if !strings.HasSuffix(s, "\033\\") {
+		t.Fatalf("output does not end with ST: %q", s[maxInt(0, len(s)-10):])
+	}
+	var ctrl, payload []string
+	for _, seq := range strings.Split(strings.TrimSuffix(s, "\033\\"), "\033\\") {
+		if !strings.HasPrefix(seq, "\033_G") {
+			t.Fatalf("chunk does not start with APC G: %q", seq[:minInt(len(seq), 10)])
+		}
+		parts := strings.SplitN(strings.TrimPrefix(seq, "\033_G"), ";", 2)
+		if len(parts) != 2 {
+			t.Fatalf("chunk without payload: %q", seq)
+		}
+		ctrl, payload = append(ctrl, parts[0]), append(payload, parts[1])
+	}
+	return ctrl, payload
+}
+
+func TestRenderKitty(t *testing.T) {
+	p := New(4, 3)
+	_ = p.ColorMode(ModeTrueColor)
+	p.PixelC(1, 2, 0x123456)
+	out := p.RenderKitty()
+	if len(out) != 1 {
+		t.Fatalf("%d lines, want 1", len(out))
+	}
+	ctrl, payload := kittyChunks(t, out[0])
+	if len(ctrl) != 1 || ctrl[0] != "f=100,a=T,m=0" {
+		t.Fatalf("control data %q", ctrl)
+	}
+	img := decodePNG(t, payload[0])
+	if img.Bounds() != image.Rect(0, 0, 4, 3) {
+		t.Errorf("bounds %v", img.Bounds())
+	}
+	if r, g, b, _ := img.At(1, 2).RGBA(); r>>8 != 0x12 || g>>8 != 0x34 || b>>8 != 0x56 {
+		t.Errorf("pixel 1,2 is %x %x %x", r>>8, g>>8, b>>8)
+	}
+
+	//the clipping area only
+	p.SetClipping(true, 1, 1, 2, 2)
+	_, payload = kittyChunks(t, p.RenderKitty()[0])
+	img = decodePNG(t, payload[0])
+	if img.Bounds() != image.Rect(0, 0, 2, 2) {
+		t.Errorf("clipped bounds %v", img.Bounds())
+	}
+	if r, _, _, _ := img.At(0, 1).RGBA(); r>>8 != 0x12 {
+		t.Error("clipped pixel 0,1 missing")
+	}
+}
+
+func TestRenderKittyChunks(t *testing.T) {
+	//noise does not compress, the PNG needs several chunks
+	p := New(64, 64)
+	_ = p.ColorMode(ModeTrueColor)
+	rnd := rand.New(rand.NewSource(1))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			p.PixelC(x, y, rnd.Uint32()&0xffffff)
+		}
+	}
+	ctrl, payload := kittyChunks(t, p.RenderKitty()[0])
+	if len(ctrl) < 3 {
+		t.Fatalf("%d chunks, want at least 3", len(ctrl))
+	}
+	for i, c := range ctrl {
+		want := "m=1"
+		switch {
+		case i == 0:
+			want = "f=100,a=T,m=1"
+		case i == len(ctrl)-1:
+			want = "m=0"
+		}
+		if c != want {
+			t.Errorf("chunk %d control %q, want %q", i, c, want)
+		}
+		if i < len(ctrl)-1 && len(payload[i]) != KittyChunk {
+			t.Errorf("chunk %d has %d bytes, want %d", i, len(payload[i]), KittyChunk)
+		}
+	}
+	if last := len(payload[len(payload)-1]); last == 0 || last > KittyChunk {
+		t.Errorf("last chunk has %d bytes", last)
+	}
+	img := decodePNG(t, strings.Join(payload, ""))
+	if r, g, b, _ := img.At(63, 63).RGBA(); (r>>8)<<16|(g>>8)<<8|b>>8 != p.matrix[63][63] {
+		t.Error("pixel 63,63 differs")
+	}
+}
+
+func TestRenderITerm(t *testing.T) {
+	p := New(5, 2)
+	p.Pixel(4, 1, true)
+	out := p.RenderITerm()
+	if len(out) != 1 {
+		t.Fatalf("%d lines, want 1", len(out))
+	}
+	const head = "\033]1337;File=inline=1;size="
+	s := out[0]
+	if !strings.HasPrefix(s, head) || !strings.HasSuffix(s, "\a") {
+		t.Fatalf("framing %q", s)
+	}
+	args := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(s, head), "\a"), ":", 2)
+	size, data := strings.TrimSuffix(args[0], ";preserveAspectRatio=1"), args[1]
+	raw, _ := base64.StdEncoding.DecodeString(data)
+	if n, err := strconv.Atoi(size); err != nil || n != len(raw) {
+		t.Errorf("size %q, PNG has %d bytes", size, len(raw))
+	}
+	img := decodePNG(t, data)
+	if img.Bounds() != image.Rect(0, 0, 5, 2) {
+		t.Errorf("bounds %v", img.Bounds())
+	}
+	//ModeNoColor is white on black
+	if r, _, _, _ := img.At(4, 1).RGBA(); r>>8 != 0xff {
+		t.Error("set pixel is not white")
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r != 0 {
+		t.Error("unset pixel is not black")
+	}
+}
@@ -0,0 +1,577 @@
+package pixelding
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// svgArgs number of arguments per SVG path command
+var svgArgs = map[byte]int{
+	'M': 2, 'm': 2,
+	'L': 2, 'l': 2,
+	'H': 1, 'h': 1,
+	'V': 1, 'v': 1,
+	'C': 6, 'c': 6,
+	'S': 4, 's': 4,
+	'Q': 4, 'q': 4,
+	'T': 2, 't': 2,
+	'A': 7, 'a': 7,
+	'Z': 0, 'z': 0,
+}
+
+// SVGParseError is returned by ParseSVGPath if the path string is malformed.
+// Offset is the byte offset of the offending Token in the path string, Command the
+// active path command and Expected the number of arguments that command needs
+type SVGParseError struct {
+	Offset   int
+	Token    string
+	Command  string
+	Expected int
+	Msg      string
+}
+
+// Error implements the error interface
+// ----------------------------------------------------------------------------------------------------------------------
+func (e *SVGParseError) Error() string {
+	tok := e.Token
+	if tok == "" {
+		tok = "end of path"
+	}
+	if e.Command == "" {
+		return fmt.Sprintf("svg path: %s at offset %d (%q)", e.Msg, e.Offset, tok)
+	}
+	return fmt.Sprintf("svg path: %s at offset %d (%q), command %s expects %d arguments", e.Msg, e.Offset, tok, e.Command, e.Expected)
+}
+
+// PathSegment is one command of a parsed SVG path with all its arguments.
+// Implicit command repetitions are split into separate segments, additional
+// coordinate pairs after a moveto become linetos as defined by the SVG spec
+type PathSegment struct {
+	Cmd    byte
+	Args   []float64
+	Offset int
+}
+
+// Path is a validated SVG path, see ParseSVGPath
+type Path struct {
+	Segments []PathSegment
+}
+
+// ParseSVGPath parses and validates an SVG path string. On malformed input a *SVGParseError
+// with the byte offset and the offending token is returned
+// ----------------------------------------------------------------------------------------------------------------------
+func ParseSVGPath(s string) (*Path, error) {
+	path, err := parseSVGPath(s)
+	if err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// parseSVGPath internal, parses an SVG path string, on malformed input the segments before the
+// error are returned together with the error
+// ----------------------------------------------------------------------------------------------------------------------
+func parseSVGPath(s string) (*Path, error) {
+	path := &Path{}
+	i := svgSkip(s, 0)
+	for i < len(s) {
+		c := s[i]
+		n, ok := svgArgs[c]
+		if !ok {
+			if _, _, isNum := svgNumber(s, i); isNum {
+				return path, &SVGParseError{Offset: i, Token: svgToken(s, i), Msg: "number without command"}
+			}
+			return path, &SVGParseError{Offset: i, Token: svgToken(s, i), Msg: "unknown command"}
+		}
+		if len(path.Segments) == 0 && c != 'M' && c != 'm' {
+			return path, &SVGParseError{Offset: i, Token: svgToken(s, i), Msg: "path must start with a moveto"}
+		}
+		cmdOffset := i
+		i = svgSkip(s, i+1)
+		if n == 0 {
+			path.Segments = append(path.Segments, PathSegment{Cmd: c, Offset: cmdOffset})
+			continue
+		}
+		groups := 0
+		for {
+			j := i
+			args := make([]float64, 0, n)
+			for k := 0; k < n; k++ {
+				j = svgSkip(s, j)
+				var v float64
+				var e int
+				if (c == 'A' || c == 'a') && (k == 3 || k == 4) {
+					v, e, ok = svgFlag(s, j)
+					if _, _, isNum := svgNumber(s, j); !ok && isNum {
+						return path, &SVGParseError{Offset: j, Token: svgToken(s, j), Command: string(c), Expected: n, Msg: "invalid arc flag"}
+					}
+				} else {
+					v, e, ok = svgNumber(s, j)
+				}
+				if !ok {
+					if k == 0 && groups > 0 {
+						break
+					}
+					return path, &SVGParseError{Offset: j, Token: svgToken(s, j), Command: string(c), Expected: n,
+						Msg: fmt.Sprintf("missing argument %d", k+1)}
+				}
+				args = append(args, v)
+				j = e
+			}
+			if len(args) < n {
+				break
+			}
+			cmd := c
+			if groups > 0 && c == 'M' {
+				cmd = 'L'
+			}
+			if groups > 0 && c == 'm' {
+				cmd = 'l'
+			}
+			path.Segments = append(path.Segments, PathSegment{Cmd: cmd, Args: args, Offset: i})
+			groups++
+			i = svgSkip(s, j)
+		}
+	}
+	return path, nil
+}
+
+// isSVGCommand internal
+// ----------------------------------------------------------------------------------------------------------------------
+func isSVGCommand(c byte) bool {
+	_, ok := svgArgs[c]
+	return ok
+}
+
+// svgSkip internal, skips white space and comma separators
+// ----------------------------------------------------------------------------------------------------------------------
+func svgSkip(s string, i int) int {
+	for i < len(s) {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r', '\f', ',':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// svgToken internal, returns the token starting at i for error messages
+// ----------------------------------------------------------------------------------------------------------------------
+func svgToken(s string, i int) string {
+	if i >= len(s) {
+		return ""
+	}
+	if _, e, ok := svgNumber(s, i); ok {
+		return s[i:e]
+	}
+	j := i + 1
+	for j < len(s) && svgSkip(s, j) == j && !isSVGCommand(s[j]) && (s[j] < '0' || s[j] > '9') {
+		j++
+	}
+	return s[i:j]
+}
+
+// svgNumber internal, scans a number as defined by the SVG path grammar at i
+// ----------------------------------------------------------------------------------------------------------------------
+func svgNumber(s string, i int) (float64, int, bool) {
+	j := i
+	if j < len(s) && (s[j] == '+' || s[j] == '-') {
+		j++
+	}
+	digits := 0
+	for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+		j++
+		digits++
+	}
+	if j < len(s) && s[j] == '.' {
+		j++
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, i, false
+	}
+	if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+		k := j + 1
+		if k < len(s) && (s[k] == '+' || s[k] == '-') {
+			k++
+		}
+		if k < len(s) && s[k] >= '0' && s[k] <= '9' {
+			for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+				k++
+			}
+			j = k
+		}
+	}
+	v, err := strconv.ParseFloat(s[i:j], 64)
+	if err != nil {
+		return 0, i, false
+	}
+	return v, j, true
+}
+
+// svgFlag internal, scans a single arc flag (0 or 1) at i
+// ----------------------------------------------------------------------------------------------------------------------
+func svgFlag(s string, i int) (float64, int, bool) {
+	if i < len(s) {
+		switch s[i] {
+		case '0':
+			return 0, i + 1, true
+		case '1':
+			return 1, i + 1, true
+		}
+	}
+	return 0, i, false
+}
+
+// PixelPath is a preparsed SVG path that can be drawn many times without parsing the
+// path string again. All commands are normalized to absolute moveto, lineto, quadratic
+// and cubic curves (arcs are converted to cubic curves), so the affine transformation
+// set on the path applies exactly to every segment
+type PixelPath struct {
+	segs []pathSeg
+	m    [6]float64
+}
+
+// pathSeg internal, normalized path segment 'M', 'L', 'Q', 'C' or 'Z'. The last point
+// is always the end point, Z holds the start point of the closed sub path
+type pathSeg struct {
+	cmd byte
+	pts [][2]float64
+}
+
+// identityMatrix internal, affine matrix a,b,c,d,e,f as used by SVG
+var identityMatrix = [6]float64{1, 0, 0, 1, 0, 0}
+
+// NewPixelPath parses the SVG path string once and returns a reusable PixelPath
+// ----------------------------------------------------------------------------------------------------------------------
+func NewPixelPath(s string) (*PixelPath, error) {
+	path, err := ParseSVGPath(s)
+	if err != nil {
+		return nil, err
+	}
+	return path.PixelPath(), nil
+}
+
+// PixelPath converts a parsed path into a reusable PixelPath
+// ----------------------------------------------------------------------------------------------------------------------
+func (path *Path) PixelPath() *PixelPath {
+	var cx, cy float64   //current point
+	var sx, sy float64   //start of the sub path
+	var lcx, lcy float64 //last control point
+	var last byte
+	pp := &PixelPath{m: identityMatrix}
+
+	for _, seg := range path.Segments {
+		a := seg.Args
+		var ox, oy float64
+		if seg.Cmd >= 'a' && seg.Cmd <= 'z' {
+			ox, oy = cx, cy
+		}
+		//reflect the last control point only if the previous segment was of the same curve type
+		rx, ry := cx, cy
+		switch {
+		case (seg.Cmd == 'S' || seg.Cmd == 's') && last == 'C',
+			(seg.Cmd == 'T' || seg.Cmd == 't') && last == 'Q':
+			rx, ry = 2*cx-lcx, 2*cy-lcy
+		}
+
+		switch seg.Cmd {
+		case 'M', 'm':
+			cx, cy = ox+a[0], oy+a[1]
+			sx, sy = cx, cy
+			pp.segs = append(pp.segs, pathSeg{'M', [][2]float64{{cx, cy}}})
+			last = 'M'
+		case 'L', 'l':
+			cx, cy = ox+a[0], oy+a[1]
+			pp.segs = append(pp.segs, pathSeg{'L', [][2]float64{{cx, cy}}})
+			last = 'L'
+		case 'H', 'h':
+			cx = ox + a[0]
+			pp.segs = append(pp.segs, pathSeg{'L', [][2]float64{{cx, cy}}})
+			last = 'L'
+		case 'V', 'v':
+			cy = oy + a[0]
+			pp.segs = append(pp.segs, pathSeg{'L', [][2]float64{{cx, cy}}})
+			last = 'L'
+		case 'C', 'c':
+			lcx, lcy = ox+a[2], oy+a[3]
+			cx, cy = ox+a[4], oy+a[5]
+			pp.segs = append(pp.segs, pathSeg{'C', [][2]float64{{ox + a[0], oy + a[1]}, {lcx, lcy}, {cx, cy}}})
+			last = 'C'
+		case 'S', 's':
+			lcx, lcy = ox+a[0], oy+a[1]
+			cx, cy = ox+a[2], oy+a[3]
+			pp.segs = append(pp.segs, pathSeg{'C', [][2]float64{{rx, ry}, {lcx, lcy}, {cx, cy}}})
+			last = 'C'
+		case 'Q', 'q':
+			lcx, lcy = ox+a[0], oy+a[1]
+			cx, cy = ox+a[2], oy+a[3]
+			pp.segs = append(pp.segs, pathSeg{'Q', [][2]float64{{lcx, lcy}, {cx, cy}}})
+			last = 'Q'
+		case 'T', 't':
+			lcx, lcy = rx, ry
+			cx, cy = ox+a[0], oy+a[1]
+			pp.segs = append(pp.segs, pathSeg{'Q', [][2]float64{{lcx, lcy}, {cx, cy}}})
+			last = 'Q'
+		case 'A', 'a':
+			ex, ey := ox+a[5], oy+a[6]
+			pp.segs = append(pp.segs, arcToCubics(cx, cy, a[0], a[1], a[2], a[3] != 0, a[4] != 0, ex, ey)...)
+			cx, cy = ex, ey
+			last = 'A'
+		case 'Z', 'z':
+			pp.segs = append(pp.segs, pathSeg{'Z', [][2]float64{{sx, sy}}})
+			cx, cy = sx, sy
+			last = 'Z'
+		}
+	}
+	return pp
+}
+
+// arcToCubics internal, approximates an SVG endpoint arc with cubic curves of at most 90 degree each
+// ----------------------------------------------------------------------------------------------------------------------
+func arcToCubics(x1, y1, rx, ry, rot float64, large, sweep bool, x2, y2 float64) []pathSeg {
+	cx, cy, rx, ry, phi, theta1, dtheta, ok := svgArcCenter(x1, y1, rx, ry, rot, large, sweep, x2, y2)
+	if !ok {
+		if x1 == x2 && y1 == y2 {
+			return nil
+		}
+		return []pathSeg{{'L', [][2]float64{{x2, y2}}}}
+	}
+	cosPhi := math.Cos(phi)
+	sinPhi := math.Sin(phi)
+	point := func(t float64) (float64, float64) {
+		return cx + rx*cosPhi*math.Cos(t) - ry*sinPhi*math.Sin(t),
+			cy + rx*sinPhi*math.Cos(t) + ry*cosPhi*math.Sin(t)
+	}
+	deriv := func(t float64) (float64, float64) {
+		return -rx*cosPhi*math.Sin(t) - ry*sinPhi*math.Cos(t),
+			-rx*sinPhi*math.Sin(t) + ry*cosPhi*math.Cos(t)
+	}
+
+	n := int(math.Ceil(math.Abs(dtheta) / (math.Pi / 2)))
+	if n < 1 {
+		n = 1
+	}
+	d := dtheta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(d/4)
+	segs := make([]pathSeg, 0, n)
+	t := theta1
+	px, py := x1, y1
+	for i := 0; i < n; i++ {
+		dx1, dy1 := deriv(t)
+		ex, ey := point(t + d)
+		dx2, dy2 := deriv(t + d)
+		if i == n-1 {
+			ex, ey = x2, y2
+		}
+		segs = append(segs, pathSeg{'C', [][2]float64{{px + k*dx1, py + k*dy1}, {ex - k*dx2, ey - k*dy2}, {ex, ey}}})
+		px, py = ex, ey
+		t += d
+	}
+	return segs
+}
+
+// Transform applies the affine matrix a,b,c,d,e,f (SVG notation) after the current transformation
+// x' = a*x + c*y + e
+// y' = b*x + d*y + f
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Transform(a, b, c, d, e, f float64) {
+	m := pp.m
+	pp.m = [6]float64{
+		a*m[0] + c*m[1],
+		b*m[0] + d*m[1],
+		a*m[2] + c*m[3],
+		b*m[2] + d*m[3],
+		a*m[4] + c*m[5] + e,
+		b*m[4] + d*m[5] + f,
+	}
+}
+
+// Translate moves the path by tx,ty
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Translate(tx, ty float64) {
+	pp.Transform(1, 0, 0, 1, tx, ty)
+}
+
+// Scale scales the path by sx,sy relative to the origin
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Scale(sx, sy float64) {
+	pp.Transform(sx, 0, 0, sy, 0, 0)
+}
+
+// Rotate rotates the path by angle degrees around the origin
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Rotate(angle float64) {
+	r := angle * (math.Pi / 180.0)
+	pp.Transform(math.Cos(r), math.Sin(r), -math.Sin(r), math.Cos(r), 0, 0)
+}
+
+// Skew skews the path by ax degrees along the x axis and ay degrees along the y axis
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Skew(ax, ay float64) {
+	pp.Transform(1, math.Tan(ay*(math.Pi/180.0)), math.Tan(ax*(math.Pi/180.0)), 1, 0, 0)
+}
+
+// ResetTransform removes all transformations from the path
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) ResetTransform() {
+	pp.m = identityMatrix
+}
+
+// apply internal, transforms a point by the path matrix
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) apply(pt [2]float64) [2]float64 {
+	m := pp.m
+	return [2]float64{m[0]*pt[0] + m[2]*pt[1] + m[4], m[1]*pt[0] + m[3]*pt[1] + m[5]}
+}
+
+// Bounds returns the exact bounding box x0,y0 to x1,y1 of the transformed path
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Bounds() (x0, y0, x1, y1 float64) {
+	first := true
+	add := func(pt [2]float64) {
+		if first {
+			x0, y0, x1, y1 = pt[0], pt[1], pt[0], pt[1]
+			first = false
+			return
+		}
+		x0 = math.Min(x0, pt[0])
+		y0 = math.Min(y0, pt[1])
+		x1 = math.Max(x1, pt[0])
+		y1 = math.Max(y1, pt[1])
+	}
+	var cur [2]float64
+	for _, seg := range pp.segs {
+		pts := make([][2]float64, 0, 4)
+		pts = append(pts, cur)
+		for _, pt := range seg.pts {
+			pts = append(pts, pp.apply(pt))
+		}
+		if seg.cmd == 'Q' || seg.cmd == 'C' {
+			for _, t := range bezierExtrema(pts) {
+				add(bezierPoint(pts, t))
+			}
+		}
+		cur = pts[len(pts)-1]
+		add(cur)
+	}
+	return
+}
+
+// bezierPoint internal, evaluates a quadratic (3 points) or cubic (4 points) curve at t
+// ----------------------------------------------------------------------------------------------------------------------
+func bezierPoint(pts [][2]float64, t float64) [2]float64 {
+	a := 1 - t
+	if len(pts) == 3 {
+		a, b, c := a*a, 2*t*a, t*t
+		return [2]float64{
+			a*pts[0][0] + b*pts[1][0] + c*pts[2][0],
+			a*pts[0][1] + b*pts[1][1] + c*pts[2][1],
+		}
+	}
+	d := t
+	b, c := a*a, d*d
+	a, b, c, d = a*b, 3*b*d, 3*a*c, c*d
+	return [2]float64{
+		a*pts[0][0] + b*pts[1][0] + c*pts[2][0] + d*pts[3][0],
+		a*pts[0][1] + b*pts[1][1] + c*pts[2][1] + d*pts[3][1],
+	}
+}
+
+// bezierExtrema internal, returns the curve parameters in (0,1) where x or y have a local extremum
+// ----------------------------------------------------------------------------------------------------------------------
+func bezierExtrema(pts [][2]float64) []float64 {
+	var ts []float64
+	add := func(t float64) {
+		if t > 0 && t < 1 {
+			ts = append(ts, t)
+		}
+	}
+	for axis := 0; axis < 2; axis++ {
+		if len(pts) == 3 {
+			den := pts[0][axis] - 2*pts[1][axis] + pts[2][axis]
+			if den != 0 {
+				add((pts[0][axis] - pts[1][axis]) / den)
+			}
+			continue
+		}
+		p0, p1, p2, p3 := pts[0][axis], pts[1][axis], pts[2][axis], pts[3][axis]
+		a := -p0 + 3*p1 - 3*p2 + p3
+		b := 2 * (p0 - 2*p1 + p2)
+		c := p1 - p0
+		if math.Abs(a) < 1e-12 {
+			if b != 0 {
+				add(-c / b)
+			}
+			continue
+		}
+		disc := b*b - 4*a*c
+		if disc < 0 {
+			continue
+		}
+		sq := math.Sqrt(disc)
+		add((-b + sq) / (2 * a))
+		add((-b - sq) / (2 * a))
+	}
+	return ts
+}
+
+// Flatten converts the transformed path into polylines, one per sub path. Every curve
+// is split into steps lines, use the same value as SetStep for the pixelDING resolution
+// ----------------------------------------------------------------------------------------------------------------------
+func (pp *PixelPath) Flatten(steps int) [][][2]float64 {
+	var lines [][][2]float64
+	var line [][2]float64
+	var cur [2]float64
+	if steps < 1 {
+		steps = DefStep
+	}
+	flush := func() {
+		if len(line) > 1 {
+			lines = append(lines, line)
+		}
+		line = nil
+	}
+	for _, seg := range pp.segs {
+		pts := make([][2]float64, 0, 4)
+		pts = append(pts, cur)
+		for _, pt := range seg.pts {
+			pts = append(pts, pp.apply(pt))
+		}
+		switch seg.cmd {
+		case 'M':
+			flush()
+			line = append(line, pts[1])
+		case 'L':
+			line = append(line, pts[1])
+		case 'Q', 'C':
+			for i := 1; i <= steps; i++ {
+				line = append(line, bezierPoint(pts, float64(i)/float64(steps)))
+			}
+		case 'Z':
+			line = append(line, pts[1])
+			flush()
+			//a path continuing after Z starts at the closed sub path start
+			line = append(line, pts[1])
+		}
+		cur = pts[len(pts)-1]
+	}
+	flush()
+	return lines
+}
+
+// DrawPath draws a PixelPath at x,y using the curve steps set by SetStep
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) DrawPath(path *PixelPath, x0, y0 float64, set bool) {
+	for _, line := range path.Flatten(p.msteps) {
+		for i := 1; i < len(line); i++ {
+			p.Line(int(line[i-1][0]+x0), int(line[i-1][1]+y0), int(line[i][0]+x0), int(line[i][1]+y0), set)
+		}
+	}
+}
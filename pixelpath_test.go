@@ -0,0 +1,84 @@
+package pixelding
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSVGPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		cmds string
+		args [][]float64
+	}{
+		{"absolute", "M10 20L30 40", "ML", [][]float64{{10, 20}, {30, 40}}},
+		{"moveto repetition", "M0 0 10 0 20 5", "MLL", [][]float64{{0, 0}, {10, 0}, {20, 5}}},
+		{"relative moveto repetition", "m1 1 2 2", "ml", [][]float64{{1, 1}, {2, 2}}},
+		{"lineto repetition", "M0 0L1 1 2 2z", "MLLz", [][]float64{{0, 0}, {1, 1}, {2, 2}, nil}},
+		{"exponent", "M1e2 2E-1l-1.5e+1,3", "Ml", [][]float64{{100, 0.2}, {-15, 3}}},
+		{"compact numbers", "M.5.5-1-2", "ML", [][]float64{{0.5, 0.5}, {-1, -2}}},
+		{"arc flags", "M0 0A1 1 0 0 1 2 2", "MA", [][]float64{{0, 0}, {1, 1, 0, 0, 1, 2, 2}}},
+		{"compact arc flags", "M2 12a10 10 0 1020 0", "Ma", [][]float64{{2, 12}, {10, 10, 0, 1, 0, 20, 0}}},
+		{"compact arc flags no separator", "M0 0a5 5 30 11.5.5", "Ma", [][]float64{{0, 0}, {5, 5, 30, 1, 1, 0.5, 0.5}}},
+		{"arc repetition", "M0 0a1 1 0 0 0 1 1 1 1 0 1 1 2 2", "Maa",
+			[][]float64{{0, 0}, {1, 1, 0, 0, 0, 1, 1}, {1, 1, 0, 1, 1, 2, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ParseSVGPath(tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			cmds := ""
+			var args [][]float64
+			for _, seg := range path.Segments {
+				cmds += string(seg.Cmd)
+				args = append(args, seg.Args)
+			}
+			if cmds != tt.cmds {
+				t.Errorf("commands %q, want %q", cmds, tt.cmds)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("arguments %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestParseSVGPathErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		offset   int
+		token    string
+		command  string
+		expected int
+	}{
+		{"missing argument", "M 10 10 L 20", 12, "", "L", 2},
+		{"missing curve argument", "M1 1 C1 2 3", 11, "", "C", 6},
+		{"no moveto", "L1 1", 0, "L", "", 0},
+		{"unknown command", "M1 1 X2", 5, "X", "", 0},
+		{"number without command", "1 2", 0, "1", "", 0},
+		{"invalid arc flag", "M0 0 A1 1 0 2 0 1 1", 12, "2", "A", 7},
+		{"bad number", "M1 1 L2 -", 8, "-", "L", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := ParseSVGPath(tt.path)
+			if path != nil {
+				t.Error("path returned with error")
+			}
+			var perr *SVGParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("error %v is no *SVGParseError", err)
+			}
+			if perr.Offset != tt.offset || perr.Token != tt.token || perr.Command != tt.command ||
+				perr.Expected != tt.expected {
+				t.Errorf("got offset %d token %q command %q expected %d, want %d %q %q %d", perr.Offset,
+					perr.Token, perr.Command, perr.Expected, tt.offset, tt.token, tt.command, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package pixelding
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+)
+
+const (
+	DitherNone = iota
+	DitherFloydSteinberg
+	DitherOrdered
+)
+
+// bayerMatrix the 4x4 threshold map for the ordered dithering
+var bayerMatrix = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// paletteColors the RGB values of the 256 color palette
+var paletteColors = func() []uint32 {
+	colors := make([]uint32, 256)
+	for i := range colors {
+		colors[i] = paletteColor(uint32(i))
+	}
+	return colors
+}()
+
+// PictureOptions controls the conversion of an image into a PixelPicture
+type PictureOptions struct {
+	Mode       int    // color mode of the picture data, ModeNoColor stores 0 and 1
+	SizeX      int    // target width, 0 keeps the width or the aspect ratio to SizeY
+	SizeY      int    // target height, 0 keeps the height or the aspect ratio to SizeX
+	SegX       int    // segment width, 0 is the full picture width
+	SegY       int    // segment height, 0 is the full picture height
+	ColorKey   uint32 // value stored for transparent pixels, the next free value if an opaque pixel has it
+	AlphaLimit uint8  // pixels with an alpha below are transparent (UseColorKey), 0 ignores the alpha channel
+	Alpha      bool   // ModeTrueColor only, stores ARGB data for blending
+	Dither     int    // DitherNone, DitherFloydSteinberg or DitherOrdered, not used for ModeTrueColor
+}
+
+// PictureFromImage converts an image into a PixelPicture, resampled to the target size of the options.
+// Without options the picture is a true color picture of the image size
+// ----------------------------------------------------------------------------------------------------------------------
+func PictureFromImage(img image.Image, opts *PictureOptions) *PixelPicture {
+	o := PictureOptions{Mode: ModeTrueColor}
+	if opts != nil {
+		o = *opts
+	}
+	b := img.Bounds()
+	w, h := o.SizeX, o.SizeY
+	switch {
+	case w <= 0 && h <= 0:
+		w, h = b.Dx(), b.Dy()
+	case w <= 0:
+		w = maxInt(1, int(math.Round(float64(h)*float64(b.Dx())/float64(maxInt(b.Dy(), 1)))))
+	case h <= 0:
+		h = maxInt(1, int(math.Round(float64(w)*float64(b.Dy())/float64(maxInt(b.Dx(), 1)))))
+	}
+
+	px := resampleImage(img, w, h)
+	pic := &PixelPicture{Mode: o.Mode, ColorKey: o.ColorKey, SizeX: w, SizeY: h, SegX: o.SegX, SegY: o.SegY}
+	pic.UseColorKey = o.AlphaLimit > 0
+	pic.Alpha = o.Alpha && o.Mode == ModeTrueColor
+	if pic.SegX <= 0 {
+		pic.SegX = w
+	}
+	if pic.SegY <= 0 {
+		pic.SegY = h
+	}
+	pic.Data = make([]uint32, w*h)
+	var keyed []int
+	used := map[uint32]bool{}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if px[i][3] < float64(o.AlphaLimit) {
+				keyed = append(keyed, i)
+				continue
+			}
+			c := px[i]
+			if o.Dither == DitherOrdered && o.Mode != ModeTrueColor {
+				d := (bayerMatrix[y%4][x%4]/16 - 0.5) * ditherSpread(o.Mode)
+				c = [4]float64{c[0] + d, c[1] + d, c[2] + d, c[3]}
+			}
+			v, rgb := quantizePixel(c, o.Mode)
+			pic.Data[i] = v
+			if pic.Alpha {
+				pic.Data[i] |= uint32(math.Round(c[3])) << 24
+			}
+			if o.Dither == DitherFloydSteinberg && o.Mode != ModeTrueColor {
+				var e [3]float64
+				for k := 0; k < 3; k++ {
+					e[k] = c[k] - float64((rgb>>(16-8*k))&0xff)
+				}
+				diffuseError(px, w, h, x+1, y, e, 7.0/16)
+				diffuseError(px, w, h, x-1, y+1, e, 3.0/16)
+				diffuseError(px, w, h, x, y+1, e, 5.0/16)
+				diffuseError(px, w, h, x+1, y+1, e, 1.0/16)
+			}
+			if o.AlphaLimit > 0 {
+				used[pic.Data[i]] = true
+			}
+		}
+	}
+	//the ColorKey must not hide an opaque pixel of the same value
+	for used[pic.ColorKey] {
+		pic.ColorKey++
+	}
+	for _, i := range keyed {
+		pic.Data[i] = pic.ColorKey
+	}
+	return pic
+}
+
+// LoadImage loads a PNG, GIF or JPEG file and converts it into a PixelPicture, see PictureFromImage
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) LoadImage(name string, opts *PictureOptions) *PixelPicture {
+	file, err := os.Open(name)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	return PictureFromImage(img, opts)
+}
+
+// resampleImage internal, scales the image to w,h and returns the non premultiplied RGBA values row by row.
+// Downscaling averages all covered source pixels, upscaling takes the nearest source pixel
+// ----------------------------------------------------------------------------------------------------------------------
+func resampleImage(img image.Image, w, h int) [][4]float64 {
+	b := img.Bounds()
+	fx := float64(b.Dx()) / float64(w)
+	fy := float64(b.Dy()) / float64(h)
+	px := make([][4]float64, w*h)
+	for y := 0; y < h; y++ {
+		sy1 := b.Min.Y + int(float64(y)*fy)
+		sy2 := maxInt(b.Min.Y+int(float64(y+1)*fy), sy1+1)
+		for x := 0; x < w; x++ {
+			sx1 := b.Min.X + int(float64(x)*fx)
+			sx2 := maxInt(b.Min.X+int(float64(x+1)*fx), sx1+1)
+			var r, g, bl, a float64
+			n := 0.0
+			for sy := sy1; sy < sy2; sy++ {
+				for sx := sx1; sx < sx2; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += float64(cr)
+					g += float64(cg)
+					bl += float64(cb)
+					a += float64(ca)
+					n++
+				}
+			}
+			if a == 0 {
+				px[y*w+x] = [4]float64{0, 0, 0, 0}
+				continue
+			}
+			//averaged premultiplied values, divided by the alpha sum for straight colors
+			px[y*w+x] = [4]float64{r / a * 255, g / a * 255, bl / a * 255, a / n / 257}
+		}
+	}
+	return px
+}
+
+// quantizePixel internal, returns the picture value and its RGB color of the nearest color of the mode
+// ----------------------------------------------------------------------------------------------------------------------
+func quantizePixel(c [4]float64, mode int) (uint32, uint32) {
+	cl := func(v float64) uint32 {
+		return uint32(math.Max(0, math.Min(255, math.Round(v))))
+	}
+	rgb := cl(c[0])<<16 | cl(c[1])<<8 | cl(c[2])
+	switch mode {
+	case ModeTrueColor:
+		return rgb, rgb
+	case ModePaletteColor:
+		i := nearestColor(paletteColors, rgb)
+		return uint32(i), paletteColors[i]
+	case Mode16Color:
+		i := nearestColor(ansiColors[:], rgb)
+		if i < 8 {
+			return uint32(30 + i), ansiColors[i]
+		}
+		return uint32(90 + i - 8), ansiColors[i]
+	}
+	if 0.299*c[0]+0.587*c[1]+0.114*c[2] >= 128 {
+		return 1, 0xFFFFFF
+	}
+	return 0, 0
+}
+
+// ditherSpread internal, returns the strength of the ordered dithering for the color mode
+// ----------------------------------------------------------------------------------------------------------------------
+func ditherSpread(mode int) float64 {
+	switch mode {
+	case ModePaletteColor:
+		return 48
+	case Mode16Color:
+		return 128
+	}
+	return 255
+}
+
+// diffuseError internal, adds the weighted quantization error e to the pixel x,y
+// ----------------------------------------------------------------------------------------------------------------------
+func diffuseError(px [][4]float64, w, h, x, y int, e [3]float64, f float64) {
+	if x < 0 || x >= w || y >= h {
+		return
+	}
+	for k := 0; k < 3; k++ {
+		px[y*w+x][k] += e[k] * f
+	}
+}
+
+// PictureTransform describes how PictureEx draws a picture. Flips are done first, then the quarter turns,
+// the scaling into the target rectangle and at last the rotation by Angle around the rectangle center
+type PictureTransform struct {
+	FlipH    bool    // mirror left and right
+	FlipV    bool    // mirror top and bottom
+	Turns    int     // clockwise 90 degree turns
+	Angle    float64 // clockwise rotation in degrees
+	Bilinear bool    // bilinear scaling for true color and alpha pictures, otherwise nearest pixel
+}
+
+// PictureEx draws a picture (segment) transformed into the rectangle x0,y0 to x1,y1. A segment of 0 draws
+// the full picture, the transform may be nil for a scaled copy. Errors are returned as in Picture
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) PictureEx(picture *PixelPicture, x0, y0, x1, y1 int, segment int, t *PictureTransform) error {
+	tr := PictureTransform{}
+	if t != nil {
+		tr = *t
+	}
+	sx0, sy0, sw, sh, err := p.pictureSegment(picture, segment)
+	if err != nil {
+		return err
+	}
+	x0, y0 = p.scale(x0, y0)
+	x1, y1 = p.scale(x1, y1)
+	if x1 < x0 {
+		x0, x1 = x1, x0
+	}
+	if y1 < y0 {
+		y0, y1 = y1, y0
+	}
+	w, h := float64(x1-x0+1), float64(y1-y0+1)
+
+	turns := ((tr.Turns % 4) + 4) % 4
+	ow, oh := float64(sw), float64(sh)
+	if turns%2 == 1 {
+		ow, oh = oh, ow
+	}
+	bilinear := tr.Bilinear && (picture.Mode == ModeTrueColor || picture.Alpha)
+
+	rad := tr.Angle * (math.Pi / 180.0)
+	cos, sin := math.Cos(rad), math.Sin(rad)
+	cx, cy := float64(x0)+w/2, float64(y0)+h/2
+	//bounding box of the rotated rectangle
+	ex := math.Abs(w/2*cos) + math.Abs(h/2*sin)
+	ey := math.Abs(w/2*sin) + math.Abs(h/2*cos)
+	for dy := int(math.Floor(cy - ey)); dy < int(math.Ceil(cy+ey)); dy++ {
+		for dx := int(math.Floor(cx - ex)); dx < int(math.Ceil(cx+ex)); dx++ {
+			//back to the unrotated rectangle
+			rx, ry := float64(dx)+0.5-cx, float64(dy)+0.5-cy
+			u := rx*cos + ry*sin + w/2
+			v := -rx*sin + ry*cos + h/2
+			if u < 0 || v < 0 || u >= w || v >= h {
+				continue
+			}
+			ox, oy := u*ow/w, v*oh/h
+			var px, py float64
+			switch turns {
+			case 0:
+				px, py = ox, oy
+			case 1:
+				px, py = oy, float64(sh)-ox
+			case 2:
+				px, py = float64(sw)-ox, float64(sh)-oy
+			case 3:
+				px, py = float64(sw)-oy, ox
+			}
+			if tr.FlipH {
+				px = float64(sw) - px
+			}
+			if tr.FlipV {
+				py = float64(sh) - py
+			}
+			var c uint32
+			var ok bool
+			if bilinear {
+				c, ok = picture.bilinear(sx0, sy0, sw, sh, px-0.5, py-0.5)
+			}
+			if !ok {
+				ix := minInt(maxInt(int(px), 0), sw-1)
+				iy := minInt(maxInt(int(py), 0), sh-1)
+				c = picture.Data[(sy0+iy)*picture.SizeX+sx0+ix]
+			}
+			p.pictureDot(picture, dx, dy, c)
+		}
+	}
+	return nil
+}
+
+// segmentRect internal, returns the position and size of a picture segment, 0 is the full picture.
+// The picture and segment need to be valid
+// ----------------------------------------------------------------------------------------------------------------------
+func (pic *PixelPicture) segmentRect(segment int) (int, int, int, int) {
+	if segment <= 0 || pic.SegX <= 0 || pic.SegY <= 0 {
+		return 0, 0, pic.SizeX, pic.SizeY
+	}
+	xdehn := pic.SizeX / pic.SegX
+	segment--
+	return (segment % xdehn) * pic.SegX, (segment / xdehn) * pic.SegY, pic.SegX, pic.SegY
+}
+
+// bilinear internal, interpolates the four segment pixels around x,y channel by channel.
+// Returns false if one of them is the color key
+// ----------------------------------------------------------------------------------------------------------------------
+func (pic *PixelPicture) bilinear(sx0, sy0, sw, sh int, x, y float64) (uint32, bool) {
+	fx, fy := math.Floor(x), math.Floor(y)
+	tx, ty := x-fx, y-fy
+	var c [4]uint32
+	for i := 0; i < 4; i++ {
+		ix := minInt(maxInt(int(fx)+i%2, 0), sw-1)
+		iy := minInt(maxInt(int(fy)+i/2, 0), sh-1)
+		c[i] = pic.Data[(sy0+iy)*pic.SizeX+sx0+ix]
+		if pic.transparent(c[i]) {
+			return 0, false
+		}
+	}
+	var r uint32
+	for shift := 0; shift <= 24; shift += 8 {
+		ch := func(i int) float64 {
+			return float64((c[i] >> shift) & 0xff)
+		}
+		top := ch(0)*(1-tx) + ch(1)*tx
+		bottom := ch(2)*(1-tx) + ch(3)*tx
+		r |= uint32(math.Round(top*(1-ty)+bottom*ty)) << shift
+	}
+	return r, true
+}
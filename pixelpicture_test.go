@@ -0,0 +1,137 @@
+package pixelding
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestPictureFromImageColorKey(t *testing.T) {
+	//opaque black, transparent, opaque white
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, color.NRGBA{0, 0, 0, 255})
+	img.Set(1, 0, color.NRGBA{0, 0, 0, 0})
+	img.Set(2, 0, color.NRGBA{255, 255, 255, 255})
+	tests := []struct {
+		name     string
+		opts     PictureOptions
+		key      uint32
+		bg       uint32
+		want     [3]uint32
+		drawMode int
+	}{
+		{"true color black", PictureOptions{Mode: ModeTrueColor, AlphaLimit: 128}, 1, 0x00ff00,
+			[3]uint32{0, 0x00ff00, 0xffffff}, ModeTrueColor},
+		{"true color free key", PictureOptions{Mode: ModeTrueColor, AlphaLimit: 128, ColorKey: 0xff00ff}, 0xff00ff,
+			0x00ff00, [3]uint32{0, 0x00ff00, 0xffffff}, ModeTrueColor},
+		{"no color", PictureOptions{Mode: ModeNoColor, AlphaLimit: 128}, 2, 1, [3]uint32{0, 1, 1}, ModeNoColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pic := PictureFromImage(img, &tt.opts)
+			if !pic.UseColorKey || pic.ColorKey != tt.key || pic.Data[1] != tt.key {
+				t.Errorf("UseColorKey %v ColorKey %x data %x, want key %x", pic.UseColorKey, pic.ColorKey, pic.Data,
+					tt.key)
+			}
+			p := New(3, 1)
+			if err := p.ColorMode(tt.drawMode); err != nil {
+				t.Fatal(err)
+			}
+			p.Color(tt.bg)
+			p.Rectangle(0, 0, 2, 0, true, true)
+			if err := p.Picture(pic, 0, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+			for x, want := range tt.want {
+				if p.matrix[0][x] != want {
+					t.Errorf("pixel %d is %06x, want %06x", x, p.matrix[0][x], want)
+				}
+			}
+		})
+	}
+}
+
+// drawEx internal test helper, draws a picture with PictureEx onto a true color canvas filled with bg and
+// returns the canvas rows
+func drawEx(t *testing.T, pic *PixelPicture, w, h int, x0, y0, x1, y1, segment int, tr *PictureTransform) [][]uint32 {
+	t.Helper()
+	p := New(w, h)
+	_ = p.ColorMode(ModeTrueColor)
+	p.Color(0xee)
+	p.Rectangle(0, 0, w-1, h-1, true, true)
+	if err := p.PictureEx(pic, x0, y0, x1, y1, segment, tr); err != nil {
+		t.Fatal(err)
+	}
+	return p.matrix
+}
+
+func TestPictureEx(t *testing.T) {
+	//a b c
+	//d e f
+	pic := &PixelPicture{Mode: ModeTrueColor, SizeX: 3, SizeY: 2, SegX: 3, SegY: 2, Data: []uint32{1, 2, 3, 4, 5, 6}}
+	tests := []struct {
+		name string
+		tr   *PictureTransform
+		w, h int
+		want [][]uint32
+	}{
+		{"copy", nil, 3, 2, [][]uint32{{1, 2, 3}, {4, 5, 6}}},
+		{"flip h", &PictureTransform{FlipH: true}, 3, 2, [][]uint32{{3, 2, 1}, {6, 5, 4}}},
+		{"flip v", &PictureTransform{FlipV: true}, 3, 2, [][]uint32{{4, 5, 6}, {1, 2, 3}}},
+		{"flip both", &PictureTransform{FlipH: true, FlipV: true}, 3, 2, [][]uint32{{6, 5, 4}, {3, 2, 1}}},
+		{"turn", &PictureTransform{Turns: 1}, 2, 3, [][]uint32{{4, 1}, {5, 2}, {6, 3}}},
+		{"turn twice", &PictureTransform{Turns: 2}, 3, 2, [][]uint32{{6, 5, 4}, {3, 2, 1}}},
+		{"turn back", &PictureTransform{Turns: -1}, 2, 3, [][]uint32{{3, 6}, {2, 5}, {1, 4}}},
+		{"flip then turn", &PictureTransform{FlipH: true, Turns: 1}, 2, 3, [][]uint32{{6, 3}, {5, 2}, {4, 1}}},
+		{"angle 180", &PictureTransform{Angle: 180}, 3, 2, [][]uint32{{6, 5, 4}, {3, 2, 1}}},
+		{"scaled", nil, 6, 4, [][]uint32{{1, 1, 2, 2, 3, 3}, {1, 1, 2, 2, 3, 3}, {4, 4, 5, 5, 6, 6},
+			{4, 4, 5, 5, 6, 6}}},
+		{"shrunk", nil, 3, 1, [][]uint32{{4, 5, 6}}}, //the pixel center hits the second row
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := drawEx(t, pic, tt.w, tt.h, 0, 0, tt.w-1, tt.h-1, 0, tt.tr)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPictureExSegmentKey(t *testing.T) {
+	//two segments of 2x1, the second one with a transparent pixel
+	pic := &PixelPicture{Mode: ModeTrueColor, SizeX: 4, SizeY: 1, SegX: 2, SegY: 1, ColorKey: 0xff00ff,
+		Data: []uint32{1, 2, 0xff00ff, 4}}
+	got := drawEx(t, pic, 4, 1, 0, 0, 1, 0, 2, &PictureTransform{FlipH: true})
+	if want := [][]uint32{{4, 0xee, 0xee, 0xee}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+	p := New(4, 4)
+	if err := p.PictureEx(pic, 0, 0, 1, 1, 3, nil); err == nil {
+		t.Error("segment 3 drawn")
+	}
+}
+
+func TestPictureExBilinear(t *testing.T) {
+	pic := &PixelPicture{Mode: ModeTrueColor, SizeX: 2, SizeY: 1, Data: []uint32{0x000000, 0xff0000}}
+	row := drawEx(t, pic, 8, 1, 0, 0, 7, 0, 0, &PictureTransform{Bilinear: true})[0]
+	if row[0] != 0 || row[7] != 0xff0000 {
+		t.Errorf("ends %06x %06x", row[0], row[7])
+	}
+	between := false
+	for x := 1; x < len(row); x++ {
+		if row[x]>>16 < row[x-1]>>16 || row[x]&0xffff != 0 {
+			t.Fatalf("row %x not a red ramp", row)
+		}
+		between = between || row[x] != 0 && row[x] != 0xff0000
+	}
+	if !between {
+		t.Errorf("row %x has no interpolated pixel", row)
+	}
+	//nearest pixel without Bilinear
+	row = drawEx(t, pic, 8, 1, 0, 0, 7, 0, 0, nil)[0]
+	if want := []uint32{0, 0, 0, 0, 0xff0000, 0xff0000, 0xff0000, 0xff0000}; !reflect.DeepEqual(row, want) {
+		t.Errorf("nearest row %x", row)
+	}
+}
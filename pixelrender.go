@@ -0,0 +1,389 @@
+package pixelding
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	RenderBlock = iota
+	RenderBraille
+	RenderSextant
+	RenderOctant
+	RenderQuadrant
+)
+
+const RendermodeError = "rendermode error"
+
+// quadrantGlyphs the 2x2 block chars, bit 8 is the upper left, 4 upper right, 2 lower left and 1 the lower right
+var quadrantGlyphs = []string{
+	" ",      // 0
+	"\u2597", // 1
+	"\u2596", // 2
+	"\u2584", // 3
+	"\u259D", // 4
+	"\u2590", // 5
+	"\u259E", // 6
+	"\u259F", // 7
+	"\u2598", // 8
+	"\u259A", // 9
+	"\u258C", // 10
+	"\u2599", // 11
+	"\u2580", // 12
+	"\u259C", // 13
+	"\u259B", // 14
+	"\u2588", // 15
+}
+
+// octantEncoded the octant patterns (bit 0 upper left to bit 7 lower right) which are
+// encoded outside of the octant range U+1CD00
+var octantEncoded = map[int]rune{
+	0x00: ' ', 0x01: 0x1CEA8, 0x02: 0x1CEAB, 0x03: 0x1FB82, 0x05: 0x2598, 0x0A: 0x259D,
+	0x0F: 0x2580, 0x14: 0x1FBE6, 0x28: 0x1FBE7, 0x3F: 0x1FB85, 0x40: 0x1CEA3, 0x50: 0x2596,
+	0x55: 0x258C, 0x5A: 0x259E, 0x5F: 0x259B, 0x80: 0x1CEA0, 0xA0: 0x2597, 0xA5: 0x259A,
+	0xAA: 0x2590, 0xAF: 0x259C, 0xC0: 0x2582, 0xF0: 0x2584, 0xF5: 0x2599, 0xFA: 0x259F,
+	0xFC: 0x2586, 0xFF: 0x2588,
+}
+
+var sextantGlyphs = buildSextantGlyphs()
+var octantGlyphs = buildOctantGlyphs()
+
+// RenderMode set the chars used by RenderXY
+// need to be one of : RenderBlock (default, quadrants in ModeNoColor, half blocks in the color modes),
+// RenderBraille (2x4 dots per char), RenderSextant (2x3 blocks, Unicode 13), RenderOctant (2x4 blocks, Unicode 16),
+// RenderQuadrant (2x2 blocks with two colors per char in the color modes)
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderMode(mode int) error {
+	switch mode {
+	case RenderBlock, RenderBraille, RenderSextant, RenderOctant, RenderQuadrant:
+		p.rendermode = mode
+		return nil
+	default:
+		p.LastError = errors.New(RendermodeError)
+		return p.LastError
+	}
+}
+
+// GlyphFallback renders the sextant and octant modes with the quadrant block chars
+// for terminals whose fonts lack the newer glyphs
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) GlyphFallback(b bool) {
+	p.fallback = b
+}
+
+// buildSextantGlyphs internal, the sextants U+1FB00 are ordered by pattern, without the
+// patterns already encoded as space, left half, right half and full block
+// ----------------------------------------------------------------------------------------------------------------------
+func buildSextantGlyphs() []string {
+	g := make([]string, 64)
+	r := rune(0x1FB00)
+	for bits := range g {
+		switch bits {
+		case 0:
+			g[bits] = " "
+		case 0x15:
+			g[bits] = "\u258C"
+		case 0x2A:
+			g[bits] = "\u2590"
+		case 0x3F:
+			g[bits] = "\u2588"
+		default:
+			g[bits] = string(r)
+			r++
+		}
+	}
+	return g
+}
+
+// buildOctantGlyphs internal, the octants U+1CD00 are ordered by pattern, without the
+// patterns already encoded elsewhere
+// ----------------------------------------------------------------------------------------------------------------------
+func buildOctantGlyphs() []string {
+	g := make([]string, 256)
+	r := rune(0x1CD00)
+	for bits := range g {
+		if e, ok := octantEncoded[bits]; ok {
+			g[bits] = string(e)
+		} else {
+			g[bits] = string(r)
+			r++
+		}
+	}
+	return g
+}
+
+// sextantGlyph internal
+// ----------------------------------------------------------------------------------------------------------------------
+func sextantGlyph(bits int) string {
+	return sextantGlyphs[bits]
+}
+
+// octantGlyph internal
+// ----------------------------------------------------------------------------------------------------------------------
+func octantGlyph(bits int) string {
+	return octantGlyphs[bits]
+}
+
+// fallbackGlyph internal, returns a glyph function mapping w by h dot patterns to the quadrant
+// chars. A quadrant is set if any of its dots is set, a middle row belongs to both halves
+// ----------------------------------------------------------------------------------------------------------------------
+func fallbackGlyph(w, h int) func(bits int) string {
+	table := make([]string, 1<<(w*h))
+	for bits := range table {
+		q := 0
+		for r := 0; r < h; r++ {
+			for c := 0; c < w; c++ {
+				if bits&(1<<(r*w+c)) == 0 {
+					continue
+				}
+				qc := c * 2 / w
+				if r*2 < h {
+					q |= 8 >> qc
+				}
+				if r*2+2 > h {
+					q |= 2 >> qc
+				}
+			}
+		}
+		table[bits] = quadrantGlyphs[q]
+	}
+	return func(bits int) string {
+		return table[bits]
+	}
+}
+
+// brailleGlyph internal, dot bit index (row * 2 + column) to the braille char
+// ----------------------------------------------------------------------------------------------------------------------
+func brailleGlyph(bits int) string {
+	dots := []rune{0x01, 0x08, 0x02, 0x10, 0x04, 0x20, 0x40, 0x80}
+	r := rune(0x2800)
+	for i, d := range dots {
+		if bits&(1<<i) != 0 {
+			r |= d
+		}
+	}
+	return string(r)
+}
+
+// renderCells internal, renders x1,y1 to x2,y2 with chars of w by h dots. The dot at column c and
+// row r is bit r*w+c of the glyph index. Aspect doubles the pixels, Invert flips the dots, texts
+// of the overlay replace the char. In the color modes every char gets the most used dot color
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) renderCells(x1, y1, x2, y2, w, h int, glyph func(bits int) string) []string {
+	cmp := !p.invert
+	color := p.colorrender != ModeNoColor
+	//dot d of the line or column is pixel d/f, Aspect makes every pixel f=2 dots
+	fx, fy := 1+p.aspectX, 1+p.aspectY
+	var afg uint32 = math.MaxInt32
+
+	p.buffer = []string{}
+	for cy := 0; y1+cy*h/fy < y2; cy++ {
+		y := y1 + cy*h/fy
+		stepY := maxInt(1, y1+(cy+1)*h/fy-y)
+		lo := ""
+		text := textCursor{x: -1}
+		for cx := 0; x1+cx*w/fx < x2; cx++ {
+			x := x1 + cx*w/fx
+			stepX := maxInt(1, x1+(cx+1)*w/fx-x)
+			if t := p.cellText(&text, x, y, stepX, stepY); t != 0 {
+				if color && afg != p.acolor {
+					lo = lo + p.setFG(p.acolor)
+					afg = p.acolor
+				}
+				lo = lo + string(t)
+				continue
+			}
+			bits := 0
+			counts := map[uint32]int{}
+			for r := 0; r < h; r++ {
+				for c := 0; c < w; c++ {
+					px := x1 + (cx*w+c)/fx
+					py := y1 + (cy*h+r)/fy
+					if p.getPixel(px, py) == cmp {
+						bits |= 1 << (r*w + c)
+						counts[p.getPixelC(px, py)]++
+					}
+				}
+			}
+			if color && bits != 0 {
+				fg := p.acolor
+				best := 0
+				for c, n := range counts {
+					if c != 0 && (n > best || n == best && c < fg) {
+						fg, best = c, n
+					}
+				}
+				if afg != fg {
+					lo = lo + p.setFG(fg)
+					afg = fg
+				}
+			}
+			lo = lo + glyph(bits)
+		}
+		if color {
+			lo = lo + "\033[0m"
+			afg = math.MaxInt32
+		}
+		p.buffer = append(p.buffer, lo)
+	}
+	return p.buffer
+}
+
+// textCursor internal, position of the next text overlay char of a running text in the output line,
+// x is -1 without a running text
+type textCursor struct {
+	x, y int
+}
+
+// cellText internal, returns the text overlay char shown in the cell or 0. A running text goes on with
+// its next char, otherwise the first char inside the cell starts a new one. So every overlay char gets
+// its own cell, even if a cell covers more than one column of the overlay
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) cellText(c *textCursor, x, y, w, h int) rune {
+	if c.x >= 0 && c.x < len(p.tmatrix[c.y]) && p.tmatrix[c.y][c.x] > 0 {
+		c.x++
+		return p.tmatrix[c.y][c.x-1]
+	}
+	c.x = -1
+	for dy := 0; dy < h; dy += 2 {
+		ty := (y + dy) / 2
+		if ty < 0 || ty >= len(p.tmatrix) {
+			continue
+		}
+		for dx := 0; dx < w; dx++ {
+			if x+dx >= 0 && x+dx < len(p.tmatrix[ty]) && p.tmatrix[ty][x+dx] > 0 {
+				c.x, c.y = x+dx+1, ty
+				return p.tmatrix[ty][x+dx]
+			}
+		}
+	}
+	return 0
+}
+
+// renderQuadrants internal, renders x1,y1 to x2,y2 with quadrant chars in the color modes. The four
+// pixels of every char are split into the two color groups with the smallest color error,
+// the group colors become foreground and background
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) renderQuadrants(x1, y1, x2, y2 int) []string {
+	var afg uint32 = math.MaxInt32
+	var abg uint32 = math.MaxInt32
+	stepX := 2 - p.aspectX
+	stepY := 2 - p.aspectY
+
+	p.buffer = []string{}
+	for y := y1; y < y2; y += stepY {
+		lo := ""
+		text := textCursor{x: -1}
+		for x := x1; x < x2; x += stepX {
+			if t := p.cellText(&text, x, y, stepX, stepY); t != 0 {
+				bg := p.getPixelC(x, y)
+				fg := p.getPixelC(x, y+1)
+				if abg != bg {
+					lo = lo + p.setBG(bg)
+					abg = bg
+				}
+				if afg != fg {
+					lo = lo + p.setFG(fg)
+					afg = fg
+				}
+				lo = lo + string(t)
+				continue
+			}
+			px := [4]uint32{
+				p.getPixelC(x, y),
+				p.getPixelC(x+1-p.aspectX, y),
+				p.getPixelC(x, y+1-p.aspectY),
+				p.getPixelC(x+1-p.aspectX, y+1-p.aspectY),
+			}
+			bits, fg, bg := p.splitQuadrant(px)
+			//swapped colors need no new escape codes
+			if fg == abg && bg == afg {
+				bits, fg, bg = 15-bits, bg, fg
+			}
+			if bits != 0 && afg != fg {
+				lo = lo + p.setFG(fg)
+				afg = fg
+			}
+			if bits != 15 && abg != bg {
+				lo = lo + p.setBG(bg)
+				abg = bg
+			}
+			lo = lo + quadrantGlyphs[bits]
+		}
+		lo = lo + "\033[0m"
+		afg = math.MaxInt32
+		abg = math.MaxInt32
+		p.buffer = append(p.buffer, lo)
+	}
+	return p.buffer
+}
+
+// splitQuadrant internal, splits the pixels upper left, upper right, lower left, lower right into two
+// groups and returns the quadrant bits of the foreground group with foreground and background color.
+// Each group is represented by the member color closest to the other members
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) splitQuadrant(px [4]uint32) (int, uint32, uint32) {
+	quad := [4]int{8, 4, 2, 1}
+	bestErr := -1
+	bestBits, bestFG, bestBG := 0, px[0], px[0]
+	//the upper left pixel is always background, so every split is tried once
+	for mask := 0; mask < 8; mask++ {
+		var fgs, bgs []uint32
+		bits := 0
+		for i := 0; i < 4; i++ {
+			if i > 0 && mask&(1<<(i-1)) != 0 {
+				fgs = append(fgs, px[i])
+				bits |= quad[i]
+			} else {
+				bgs = append(bgs, px[i])
+			}
+		}
+		bg, e1 := p.groupColor(bgs)
+		fg, e2 := p.groupColor(fgs)
+		if bestErr < 0 || e1+e2 < bestErr {
+			bestErr = e1 + e2
+			bestBits, bestFG, bestBG = bits, fg, bg
+		}
+		if bestErr == 0 {
+			break
+		}
+	}
+	if bestBits == 0 {
+		bestFG = bestBG
+	}
+	return bestBits, bestFG, bestBG
+}
+
+// groupColor internal, returns the member color with the smallest distance to all members and that distance
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) groupColor(colors []uint32) (uint32, int) {
+	var best uint32
+	bestErr := -1
+	for _, c := range colors {
+		e := 0
+		for _, o := range colors {
+			e += p.colorError(c, o)
+		}
+		if bestErr < 0 || e < bestErr {
+			best, bestErr = c, e
+		}
+	}
+	return best, maxInt(bestErr, 0)
+}
+
+// colorError internal, squared RGB distance in true color, otherwise 0 for equal and 1 for different colors
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) colorError(a, b uint32) int {
+	if a == b {
+		return 0
+	}
+	if p.colorrender != ModeTrueColor {
+		return 1
+	}
+	e := 0
+	for shift := 0; shift <= 16; shift += 8 {
+		d := int((a>>shift)&0xff) - int((b>>shift)&0xff)
+		e += d * d
+	}
+	return e
+}
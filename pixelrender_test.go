@@ -0,0 +1,88 @@
+package pixelding
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// plain internal test helper, removes the escape sequences of a rendered line
+func plain(s string) string {
+	return regexp.MustCompile("\033\\[[0-9;]*m").ReplaceAllString(s, "")
+}
+
+func TestRenderTextOverlay(t *testing.T) {
+	tests := []struct {
+		name   string
+		render int
+		color  int
+		line   int //output line of the text at y=4
+	}{
+		{"braille", RenderBraille, ModeNoColor, 1},
+		{"braille true color", RenderBraille, ModeTrueColor, 1},
+		{"sextant", RenderSextant, ModeNoColor, 1},
+		{"sextant true color", RenderSextant, ModeTrueColor, 1},
+		{"octant", RenderOctant, ModeNoColor, 1},
+		{"octant 16 colors", RenderOctant, Mode16Color, 1},
+		{"quadrant true color", RenderQuadrant, ModeTrueColor, 2},
+		{"quadrant palette", RenderQuadrant, ModePaletteColor, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(40, 16)
+			if err := p.ColorMode(tt.color); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.RenderMode(tt.render); err != nil {
+				t.Fatal(err)
+			}
+			p.Text(0, 0, "HELLO")
+			p.Text(10, 4, "AB")
+			lines := p.Render()
+			if got := plain(lines[0]); !strings.HasPrefix(got, "HELLO") {
+				t.Errorf("line 0 = %q, want HELLO", got)
+			}
+			if got := []rune(plain(lines[tt.line])); len(got) < 7 || string(got[5:7]) != "AB" {
+				t.Errorf("line %d = %q, want AB at cell 5", tt.line, string(got))
+			}
+		})
+	}
+}
+
+func TestRenderCellsAspect(t *testing.T) {
+	tests := []struct {
+		name   string
+		render int
+		lines  int //output lines of 6 pixel rows, doubled to 12 dot rows
+	}{
+		{"sextant", RenderSextant, 4},
+		{"braille", RenderBraille, 3},
+		{"octant", RenderOctant, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(2, 6)
+			_ = p.RenderMode(tt.render)
+			p.Aspect(0, 1)
+			p.Pixel(0, 0, true)
+			p.Pixel(1, 0, true)
+			p.Pixel(0, 5, true)
+			lines := p.Render()
+			if len(lines) != tt.lines {
+				t.Fatalf("%d lines, want %d", len(lines), tt.lines)
+			}
+			//the same dots without Aspect, every pixel row twice
+			ref := New(2, 12)
+			_ = ref.RenderMode(tt.render)
+			for _, pt := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 10}, {0, 11}} {
+				ref.Pixel(pt[0], pt[1], true)
+			}
+			want := ref.Render()
+			for i := range want {
+				if lines[i] != want[i] {
+					t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package pixelding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SixelColors the maximum number of color registers used by the sixel output
+const SixelColors = 256
+
+// colorBox internal, a box of histogram colors for the median cut quantization
+type colorBox struct {
+	colors []uint32
+	counts []int
+}
+
+// RenderSixel renders the pixelDING object as a DEC sixel image, with clipping enabled only the
+// clipping area is rendered. The text overlay is not part of the image
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderSixel() []string {
+	return p.RenderSixelXY(p.renderRegion())
+}
+
+// RenderSixelXY renders a given rectangle from pixelDING as a DEC sixel image, given by x1,y1 to x2,y2.
+// Images with more than SixelColors colors are reduced by a median cut
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) RenderSixelXY(x1, y1, x2, y2 int) []string {
+	x1, y1 = maxInt(x1, 0), maxInt(y1, 0)
+	x2, y2 = minInt(x2, p.sizeX), minInt(y2, p.sizeY)
+	w, h := maxInt(x2-x1, 0), maxInt(y2-y1, 0)
+
+	hist := map[uint32]int{}
+	for y := y1; y < y2; y++ {
+		for x := x1; x < x2; x++ {
+			hist[p.rgbColor(p.getPixelC(x, y))]++
+		}
+	}
+	palette := quantizeColors(hist, SixelColors)
+	index := map[uint32]int{}
+	for c := range hist {
+		index[c] = nearestColor(palette, c)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\033P0;1;0q")
+	sb.WriteString(fmt.Sprint("\"1;1;", w, ";", h))
+	for i, c := range palette {
+		r, g, b := (c>>16)&0xff, (c>>8)&0xff, c&0xff
+		sb.WriteString(fmt.Sprint("#", i, ";2;", (r*100+127)/255, ";", (g*100+127)/255, ";", (b*100+127)/255))
+	}
+	for by := y1; by < y2; by += 6 {
+		bands := map[int][]byte{}
+		var order []int
+		for x := x1; x < x2; x++ {
+			for r := 0; r < 6 && by+r < y2; r++ {
+				i := index[p.rgbColor(p.getPixelC(x, by+r))]
+				if bands[i] == nil {
+					bands[i] = make([]byte, w)
+					order = append(order, i)
+				}
+				bands[i][x-x1] |= 1 << r
+			}
+		}
+		for n, i := range order {
+			if n > 0 {
+				sb.WriteByte('$')
+			}
+			sb.WriteString(fmt.Sprint("#", i))
+			sixelRow(&sb, bands[i])
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\033\\")
+	p.buffer = []string{sb.String()}
+	return p.buffer
+}
+
+// sixelRow internal, writes one color row of a sixel band, run length encoded and without trailing empty sixels
+// ----------------------------------------------------------------------------------------------------------------------
+func sixelRow(sb *strings.Builder, row []byte) {
+	end := len(row)
+	for end > 0 && row[end-1] == 0 {
+		end--
+	}
+	for i := 0; i < end; {
+		j := i
+		for j < end && row[j] == row[i] {
+			j++
+		}
+		ch := string(rune(63 + row[i]))
+		if j-i > 3 {
+			sb.WriteString(fmt.Sprint("!", j-i, ch))
+		} else {
+			sb.WriteString(strings.Repeat(ch, j-i))
+		}
+		i = j
+	}
+}
+
+// quantizeColors internal, reduces the histogram colors to at most n colors by median cut
+// ----------------------------------------------------------------------------------------------------------------------
+func quantizeColors(hist map[uint32]int, n int) []uint32 {
+	box := colorBox{}
+	for c := range hist {
+		box.colors = append(box.colors, c)
+	}
+	sort.Slice(box.colors, func(i, j int) bool { return box.colors[i] < box.colors[j] })
+	if len(box.colors) <= n {
+		return box.colors
+	}
+	for _, c := range box.colors {
+		box.counts = append(box.counts, hist[c])
+	}
+
+	boxes := []colorBox{box}
+	for len(boxes) < n {
+		best, bestRange, bestShift := -1, 0, 0
+		for i, b := range boxes {
+			if len(b.colors) < 2 {
+				continue
+			}
+			for shift := 0; shift <= 16; shift += 8 {
+				lo, hi := 0xff, 0
+				for _, c := range b.colors {
+					v := int(c>>shift) & 0xff
+					lo, hi = minInt(lo, v), maxInt(hi, v)
+				}
+				if hi-lo > bestRange {
+					best, bestRange, bestShift = i, hi-lo, shift
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+		a, b := boxes[best].split(bestShift)
+		boxes[best] = a
+		boxes = append(boxes, b)
+	}
+
+	palette := make([]uint32, len(boxes))
+	for i, b := range boxes {
+		var r, g, bl, total int
+		for j, c := range b.colors {
+			r += int(c>>16&0xff) * b.counts[j]
+			g += int(c>>8&0xff) * b.counts[j]
+			bl += int(c&0xff) * b.counts[j]
+			total += b.counts[j]
+		}
+		palette[i] = uint32(r/total)<<16 | uint32(g/total)<<8 | uint32(bl/total)
+	}
+	return palette
+}
+
+// split internal, sorts the box by the channel at shift and splits it at the weighted median
+// ----------------------------------------------------------------------------------------------------------------------
+func (b colorBox) split(shift int) (colorBox, colorBox) {
+	idx := make([]int, len(b.colors))
+	total := 0
+	for i := range idx {
+		idx[i] = i
+		total += b.counts[i]
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return b.colors[idx[i]]>>shift&0xff < b.colors[idx[j]]>>shift&0xff
+	})
+	var sa colorBox
+	var sb colorBox
+	sum := 0
+	for n, i := range idx {
+		if n == 0 || (sum < total/2 && n < len(idx)-1) {
+			sa.colors = append(sa.colors, b.colors[i])
+			sa.counts = append(sa.counts, b.counts[i])
+			sum += b.counts[i]
+		} else {
+			sb.colors = append(sb.colors, b.colors[i])
+			sb.counts = append(sb.counts, b.counts[i])
+		}
+	}
+	return sa, sb
+}
+
+// nearestColor internal, returns the index of the palette color closest to c
+// ----------------------------------------------------------------------------------------------------------------------
+func nearestColor(palette []uint32, c uint32) int {
+	best, bestErr := 0, -1
+	for i, pc := range palette {
+		e := 0
+		for shift := 0; shift <= 16; shift += 8 {
+			d := int(pc>>shift&0xff) - int(c>>shift&0xff)
+			e += d * d
+		}
+		if bestErr < 0 || e < bestErr {
+			best, bestErr = i, e
+		}
+	}
+	return best
+}
@@ -0,0 +1,180 @@
+package pixelding
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sixelImage internal test helper, a decoded sixel image
+type sixelImage struct {
+	w, h    int
+	palette map[int][3]int // register to r,g,b in percent
+	pixels  map[[2]int]int // x,y to register
+}
+
+// decodeSixel internal test helper, decodes the sixel data written by RenderSixelXY
+func decodeSixel(t *testing.T, s string) sixelImage {
+	t.Helper()
+	if !strings.HasPrefix(s, "\033P0;1;0q") || !strings.HasSuffix(s, "\033\\") {
+		t.Fatalf("missing sixel introducer or terminator in %q", s)
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "\033P0;1;0q"), "\033\\")
+	img := sixelImage{palette: map[int][3]int{}, pixels: map[[2]int]int{}}
+	//number reads the decimal parameters starting at i
+	number := func(i int) ([]int, int) {
+		var v []int
+		for {
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			n, err := strconv.Atoi(s[i:j])
+			if err != nil {
+				t.Fatalf("bad number at %d in %q", i, s)
+			}
+			v = append(v, n)
+			if j >= len(s) || s[j] != ';' {
+				return v, j
+			}
+			i = j + 1
+		}
+	}
+	x, band, reg := 0, 0, -1
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			v, j := number(i + 1)
+			if len(v) != 4 {
+				t.Fatalf("raster attributes %v", v)
+			}
+			img.w, img.h = v[2], v[3]
+			i = j
+		case c == '#':
+			v, j := number(i + 1)
+			switch len(v) {
+			case 1:
+				if _, ok := img.palette[v[0]]; !ok {
+					t.Fatalf("register %d selected before defined", v[0])
+				}
+				reg = v[0]
+			case 5:
+				if v[1] != 2 {
+					t.Fatalf("color space %d, want RGB", v[1])
+				}
+				img.palette[v[0]] = [3]int{v[2], v[3], v[4]}
+			default:
+				t.Fatalf("color introducer %v", v)
+			}
+			i = j
+		case c == '$':
+			x = 0
+			i++
+		case c == '-':
+			x = 0
+			band++
+			i++
+		default:
+			n := 1
+			if c == '!' {
+				v, j := number(i + 1)
+				n, i = v[0], j
+				c = s[i]
+			}
+			if c < 63 || c > 126 {
+				t.Fatalf("bad sixel char %q at %d", c, i)
+			}
+			if reg < 0 {
+				t.Fatal("sixel data before a color register is selected")
+			}
+			for k := 0; k < n; k++ {
+				for r := 0; r < 6; r++ {
+					if (c-63)&(1<<r) != 0 {
+						if old, ok := img.pixels[[2]int{x, band*6 + r}]; ok {
+							t.Fatalf("pixel %d,%d painted twice (%d, %d)", x, band*6+r, old, reg)
+						}
+						img.pixels[[2]int{x, band*6 + r}] = reg
+					}
+				}
+				x++
+			}
+			i++
+		}
+	}
+	return img
+}
+
+func TestRenderSixelRoundTrip(t *testing.T) {
+	p := New(23, 14)
+	if err := p.ColorMode(ModeTrueColor); err != nil {
+		t.Fatal(err)
+	}
+	p.Color(0xff0000)
+	p.Rectangle(0, 0, 22, 13, true, true) //long runs for the run length encoding
+	p.Color(0x00ff00)
+	p.Line(0, 0, 13, 13, true) //crosses every band
+	p.Color(0x2040c0)
+	p.Rectangle(15, 3, 20, 9, true, false)
+	p.Color(0)
+	p.Pixel(22, 13, true) //single pixel in the last, partial band
+
+	out := p.RenderSixel()
+	if len(out) != 1 {
+		t.Fatalf("%d strings, want one", len(out))
+	}
+	for _, want := range []string{"!", "$", "-"} {
+		if !strings.Contains(out[0], want) {
+			t.Errorf("sixel data without %q", want)
+		}
+	}
+	img := decodeSixel(t, out[0])
+	if img.w != 23 || img.h != 14 {
+		t.Fatalf("raster %dx%d, want 23x14", img.w, img.h)
+	}
+	if len(img.palette) != 4 {
+		t.Errorf("%d color registers, want 4", len(img.palette))
+	}
+	for y := 0; y < 18; y++ {
+		for x := 0; x < 23; x++ {
+			reg, ok := img.pixels[[2]int{x, y}]
+			if y >= 14 {
+				if ok {
+					t.Errorf("pixel %d,%d below the image", x, y)
+				}
+				continue
+			}
+			if !ok {
+				t.Fatalf("pixel %d,%d not painted", x, y)
+			}
+			c := p.rgbColor(p.getPixelC(x, y))
+			want := [3]int{int(c>>16) & 0xff, int(c>>8) & 0xff, int(c) & 0xff}
+			got := img.palette[reg]
+			for i := range want {
+				if d := got[i]*255/100 - want[i]; d < -3 || d > 3 {
+					t.Fatalf("pixel %d,%d is %v percent, want %v", x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderSixelQuantize(t *testing.T) {
+	p := New(20, 20)
+	if err := p.ColorMode(ModeTrueColor); err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			p.Color(uint32(x*12)<<16 | uint32(y*12)<<8 | 0x40)
+			p.Pixel(x, y, true)
+		}
+	}
+	img := decodeSixel(t, p.RenderSixel()[0])
+	if len(img.palette) > SixelColors {
+		t.Errorf("%d color registers, want at most %d", len(img.palette), SixelColors)
+	}
+	if len(img.pixels) != 400 {
+		t.Errorf("%d pixels painted, want 400", len(img.pixels))
+	}
+}
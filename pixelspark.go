@@ -0,0 +1,123 @@
+package pixelding
+
+import (
+	"math"
+	"strings"
+)
+
+// VBar return a vertical bar of size eighth lines, the lines from top to bottom
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) VBar(size int) []string {
+	sx := strings.Split(VBar, "")
+	var s []string
+	if size <= 0 {
+		return s
+	}
+	if r := size % 8; r > 0 {
+		s = append(s, sx[r])
+	}
+	for i := 0; i < size/8; i++ {
+		s = append(s, sx[0])
+	}
+	return s
+}
+
+// Sparkline return a one line chart of the data using the eighth block chars.
+// Sparkline(data) normalizes to the minimum and maximum of the data
+// Sparkline(data, min, max) normalizes to the given range
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) Sparkline(data []float64, minmax ...float64) string {
+	return p.SparklineColor(data, nil, minmax...)
+}
+
+// SparklineColor return a one line chart like Sparkline, every char is colored by the ramp
+// in the current color mode, from the first color for the lowest to the last for the highest value
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) SparklineColor(data []float64, ramp []uint32, minmax ...float64) string {
+	sx := strings.Split(SparkBlocks, "")
+	lo, hi := sparkRange(data, minmax)
+	color := len(ramp) > 0 && p.colorrender != ModeNoColor
+	s := ""
+	for _, v := range data {
+		if math.IsNaN(v) {
+			s = s + " "
+			continue
+		}
+		f := sparkNorm(v, lo, hi)
+		if color {
+			s = s + p.setFG(p.rampColor(ramp, f))
+		}
+		s = s + sx[int(math.Round(f*7))]
+	}
+	if color {
+		s = s + "\033[0m"
+	}
+	return s
+}
+
+// SparklineBraille return a one line chart of the data using braille chars, every char shows
+// two values with four levels each
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) SparklineBraille(data []float64, minmax ...float64) string {
+	//braille dot bits from bottom to top, left and right column
+	left := []rune{0x40, 0x04, 0x02, 0x01}
+	right := []rune{0x80, 0x20, 0x10, 0x08}
+	lo, hi := sparkRange(data, minmax)
+	s := ""
+	for i := 0; i < len(data); i += 2 {
+		r := rune(0x2800)
+		for c, col := range [][]rune{left, right} {
+			if i+c >= len(data) || math.IsNaN(data[i+c]) {
+				continue
+			}
+			level := int(math.Round(sparkNorm(data[i+c], lo, hi) * 3))
+			for k := 0; k <= level; k++ {
+				r |= col[k]
+			}
+		}
+		s = s + string(r)
+	}
+	return s
+}
+
+// sparkRange internal, the normalization range for the spark lines
+// ----------------------------------------------------------------------------------------------------------------------
+func sparkRange(data []float64, minmax []float64) (float64, float64) {
+	if len(minmax) > 1 {
+		return minmax[0], minmax[1]
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range data {
+		if !math.IsNaN(v) {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+	}
+	return lo, hi
+}
+
+// sparkNorm internal, maps v into 0..1, a flat range maps to the middle
+// ----------------------------------------------------------------------------------------------------------------------
+func sparkNorm(v, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0.5
+	}
+	return math.Max(0, math.Min(1, (v-lo)/(hi-lo)))
+}
+
+// rampColor internal, picks the ramp color at f (0..1), true color ramps are interpolated
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) rampColor(ramp []uint32, f float64) uint32 {
+	if len(ramp) == 1 {
+		return ramp[0]
+	}
+	pos := f * float64(len(ramp)-1)
+	i := int(pos)
+	if i >= len(ramp)-1 {
+		return ramp[len(ramp)-1]
+	}
+	if p.colorrender != ModeTrueColor {
+		return ramp[int(math.Round(pos))]
+	}
+	return gradientColor(ramp[i], ramp[i+1], pos-float64(i), false)
+}
@@ -0,0 +1,101 @@
+package pixelding
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		data   []float64
+		minmax []float64
+		want   string
+	}{
+		{"rising", []float64{1, 2, 3, 4, 5, 6, 7, 8}, nil, "▁▂▃▄▅▆▇█"},
+		{"flat", []float64{5, 5, 5}, nil, "▅▅▅"},
+		{"nan", []float64{0, nan, 1}, nil, "▁ █"},
+		{"all nan", []float64{nan, nan}, nil, "  "},
+		{"min max", []float64{5, 0, 10}, []float64{0, 10}, "▅▁█"},
+		{"clipped", []float64{-5, 15}, []float64{0, 10}, "▁█"},
+		{"empty", nil, nil, ""},
+	}
+	for _, tt := range tests {
+		p := New(1, 1)
+		if got := p.Sparkline(tt.data, tt.minmax...); got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSparklineBraille(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		data   []float64
+		minmax []float64
+		want   []rune
+	}{
+		//bottom dot only, full right column, then three dots and an empty NaN column
+		{"levels", []float64{0, 1, 0.5, nan}, nil, []rune{0x2800 | 0x40 | 0x80 | 0x20 | 0x10 | 0x08, 0x2800 | 0x46}},
+		{"flat", []float64{3, 3}, nil, []rune{0x2800 | 0x46 | 0xB0}},
+		{"odd", []float64{0, 1, 1}, nil, []rune{0x2800 | 0x40 | 0xB8, 0x2800 | 0x47}},
+		{"min max", []float64{0, 10}, []float64{-10, 10}, []rune{0x2800 | 0x46 | 0xB8}},
+	}
+	for _, tt := range tests {
+		p := New(1, 1)
+		if got := p.SparklineBraille(tt.data, tt.minmax...); got != string(tt.want) {
+			t.Errorf("%s: %q, want %q", tt.name, got, string(tt.want))
+		}
+	}
+}
+
+func TestSparklineColor(t *testing.T) {
+	data := []float64{0, 0.4, 1}
+	tests := []struct {
+		name string
+		mode int
+		ramp []uint32
+		want string
+	}{
+		{"no color", ModeNoColor, []uint32{1, 2}, "▁▄█"},
+		{"no ramp", ModePaletteColor, nil, "▁▄█"},
+		{"palette", ModePaletteColor, []uint32{21, 46, 196},
+			"\033[38;5;21m▁\033[38;5;46m▄\033[38;5;196m█\033[0m"},
+		{"16 colors", Mode16Color, []uint32{34, 33, 31}, "\033[1;34m▁\033[1;33m▄\033[1;31m█\033[0m"},
+		{"one color", Mode16Color, []uint32{32}, "\033[1;32m▁\033[1;32m▄\033[1;32m█\033[0m"},
+		{"true color", ModeTrueColor, []uint32{0x000000, 0x0000ff},
+			"\033[38;2;0;0;0m▁\033[38;2;0;0;102m▄\033[38;2;0;0;255m█\033[0m"},
+	}
+	for _, tt := range tests {
+		p := New(1, 1)
+		if err := p.ColorMode(tt.mode); err != nil {
+			t.Fatal(err)
+		}
+		if got := p.SparklineColor(data, tt.ramp); got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVBar(t *testing.T) {
+	tests := []struct {
+		size int
+		want []string
+	}{
+		{0, nil},
+		{-3, nil},
+		{3, []string{"▃"}},
+		{8, []string{"█"}},
+		{11, []string{"▃", "█"}},
+		{16, []string{"█", "█"}},
+	}
+	p := New(1, 1)
+	for _, tt := range tests {
+		if got := p.VBar(tt.size); len(got) != len(tt.want) || len(got) > 0 && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("VBar(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
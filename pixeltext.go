@@ -0,0 +1,110 @@
+package pixelding
+
+import "strings"
+
+const (
+	AlignLeft = iota
+	AlignCenter
+	AlignRight
+)
+
+const (
+	AlignTop = iota
+	AlignMiddle
+	AlignBottom
+)
+
+// MeasureText returns the width and height of a text printed with FontPrint, including kerning and FontAspect.
+// The width ends at the right edge of the last char, every line separated by \n adds the LineHeight of the font
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) MeasureText(font *PixelFont, text string) (int, int) {
+	lines := strings.Split(text, "\n")
+	w := 0
+	for _, line := range lines {
+		w = maxInt(w, p.lineWidth(font, line))
+	}
+	return w, len(lines) * font.LineHeight() * (1 + p.faspectY)
+}
+
+// FontPrintBox prints a text into the box x0,y0 x1,y1 with given font. Lines are broken at \n and wrapped
+// at spaces, words wider than the box are broken between chars, lines below the box are left out.
+// alignX need to be one of : AlignLeft, AlignCenter, AlignRight
+// alignY need to be one of : AlignTop, AlignMiddle, AlignBottom
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) FontPrintBox(font *PixelFont, x0, y0, x1, y1 int, text string, set bool, alignX, alignY int) {
+	x0, x1 = minInt(x0, x1), maxInt(x0, x1)
+	y0, y1 = minInt(y0, y1), maxInt(y0, y1)
+	bw, bh := x1-x0+1, y1-y0+1
+	lh := font.LineHeight() * (1 + p.faspectY)
+	if lh <= 0 {
+		return
+	}
+	lines := p.wrapText(font, text, bw)
+	if len(lines) > bh/lh {
+		lines = lines[:bh/lh]
+	}
+	y := y0
+	switch alignY {
+	case AlignMiddle:
+		y += (bh - len(lines)*lh) / 2
+	case AlignBottom:
+		y += bh - len(lines)*lh
+	}
+	for _, line := range lines {
+		x := x0
+		switch alignX {
+		case AlignCenter:
+			x += (bw - p.lineWidth(font, line)) / 2
+		case AlignRight:
+			x += bw - p.lineWidth(font, line)
+		}
+		p.FontPrint(font, x, y, line, set)
+		y += lh
+	}
+}
+
+// lineWidth internal, returns the width of one text line from the start to the right edge of the last char
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) lineWidth(font *PixelFont, text string) int {
+	w, sx, ls := 0, 0, -1
+	for _, z := range text {
+		char := font.Chars[int(z)]
+		v := 0
+		if ls >= 0 {
+			v = font.Kern(ls, int(z))
+		}
+		w = maxInt(w, sx+(v+char.OffsetX+char.SizeX)*(1+p.faspectX))
+		sx += (char.advance() + v) * (1 + p.faspectX)
+		ls = int(z)
+	}
+	return w
+}
+
+// wrapText internal, splits a text into lines not wider than w
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) wrapText(font *PixelFont, text string, w int) []string {
+	var lines []string
+	for _, par := range strings.Split(text, "\n") {
+		line := ""
+		for i, word := range strings.Split(par, " ") {
+			if i > 0 {
+				if p.lineWidth(font, line+" "+word) <= w {
+					line += " " + word
+					continue
+				}
+				lines = append(lines, line)
+			}
+			//a word wider than the box is broken between chars
+			line = ""
+			for _, z := range word {
+				if line != "" && p.lineWidth(font, line+string(z)) > w {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(z)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+package pixelding
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMeasureText(t *testing.T) {
+	tests := []struct {
+		text             string
+		aspectX, aspectY int
+		w, h             int
+	}{
+		{"AB", 0, 0, 11, 6}, //two chars of 5 pixels, advance 6
+		{"AB\nC", 0, 0, 11, 12},
+		{"A\nABC", 0, 0, 17, 12},
+		{"", 0, 0, 0, 6},
+		{"AB", 1, 0, 22, 6},
+		{"AB", 0, 1, 11, 12},
+	}
+	for _, tt := range tests {
+		p := New(100, 40)
+		p.FontAspect(tt.aspectX, tt.aspectY)
+		if w, h := p.MeasureText(p.GetFont("__std"), tt.text); w != tt.w || h != tt.h {
+			t.Errorf("MeasureText(%q) aspect %d,%d = %d,%d, want %d,%d", tt.text, tt.aspectX, tt.aspectY, w, h,
+				tt.w, tt.h)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text string
+		w    int
+		want []string
+	}{
+		{"AB CD", 27, []string{"AB CD"}},
+		{"AB CD", 26, []string{"AB", "CD"}},
+		{"ABCDE", 17, []string{"ABC", "DE"}},
+		{"AB ABCDEF", 17, []string{"AB", "ABC", "DEF"}},
+		{"AB\nCD", 100, []string{"AB", "CD"}},
+		{"AB\n\nCD", 100, []string{"AB", "", "CD"}},
+		{"A", 1, []string{"A"}}, //a char wider than the box stays on its line
+	}
+	for _, tt := range tests {
+		p := New(100, 40)
+		if got := p.wrapText(p.GetFont("__std"), tt.text, tt.w); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.w, got, tt.want)
+		}
+	}
+}
+
+func TestFontPrintBox(t *testing.T) {
+	type line struct {
+		x, y int
+		s    string
+	}
+	//the box 10,5 to 39,22 is 30 pixels wide and holds 3 lines of 6 pixels, E and F are 4 pixels wide
+	tests := []struct {
+		name           string
+		text           string
+		alignX, alignY int
+		want           []line
+	}{
+		{"left top", "AB", AlignLeft, AlignTop, []line{{10, 5, "AB"}}},
+		{"center middle", "AB", AlignCenter, AlignMiddle, []line{{19, 11, "AB"}}},
+		{"right bottom", "AB", AlignRight, AlignBottom, []line{{29, 17, "AB"}}},
+		{"wrapped", "AB CD EF", AlignCenter, AlignTop, []line{{11, 5, "AB CD"}, {20, 11, "EF"}}},
+		{"long word", "ABCDEFGH", AlignLeft, AlignTop, []line{{10, 5, "ABCDE"}, {10, 11, "FGH"}}},
+		{"cut below", "A\nB\nC\nD", AlignLeft, AlignBottom, []line{{10, 5, "A"}, {10, 11, "B"}, {10, 17, "C"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(60, 30)
+			font := p.GetFont("__std")
+			p.FontPrintBox(font, 39, 22, 10, 5, tt.text, true, tt.alignX, tt.alignY)
+			ref := New(60, 30)
+			for _, l := range tt.want {
+				ref.FontPrint(font, l.x, l.y, l.s, true)
+			}
+			if got, want := pixels(&p), pixels(&ref); !reflect.DeepEqual(got, want) {
+				t.Errorf("%d pixels set, want %d at %v", len(got), len(want), tt.want)
+			}
+		})
+	}
+}
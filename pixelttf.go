@@ -0,0 +1,612 @@
+package pixelding
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"sort"
+)
+
+const TTFOutlineError = "unsupported font outlines"
+
+// ttfSamples the supersampling per pixel and axis used to measure the glyph coverage
+const ttfSamples = 4
+
+// TTFOptions controls the rasterization of a TrueType font
+type TTFOptions struct {
+	Height    int      // pixel height from the font ascender to the descender
+	Threshold float64  // coverage (0..1) needed to set a pixel, 0 is 0.5
+	Ranges    [][2]int // converted code point ranges (first, last), nil converts all chars of the font
+}
+
+// ttfRaster internal, the rasterization settings of one font
+type ttfRaster struct {
+	f         *ttfFont
+	scale     float64
+	threshold float64
+}
+
+// ttfFont internal, the tables of a TrueType font
+type ttfFont struct {
+	buf        []byte
+	tables     map[string][]byte
+	unitsPerEm int
+	longLoca   bool
+	numGlyphs  int
+	ascender   int
+	descender  int
+	numHMetric int
+	cff        *cffFont
+}
+
+// ttfPoint internal, a glyph outline point in font units
+type ttfPoint struct {
+	x, y    float64
+	onCurve bool
+}
+
+// LoadTTF loads a TrueType or OpenType font (.ttf, .otf with TrueType or CFF outlines) and rasterizes it into a
+// prepared pixelDING font object
+// ----------------------------------------------------------------------------------------------------------------------
+func (p *PixelDING) LoadTTF(name string, opts TTFOptions) *PixelFont {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	font, err := ParseTTF(buf, opts)
+	if err != nil {
+		p.LastError = err
+		return nil
+	}
+	return font
+}
+
+// ParseTTF rasterizes a TrueType font at the pixel height of the options. The scaled ascender and descender
+// are the Ascent and Descent of the font, the glyphs stand on the baseline, the advance width is the scaled
+// advance of the glyph. Glyphs wider than 64 pixels are cut
+// ----------------------------------------------------------------------------------------------------------------------
+func ParseTTF(buf []byte, opts TTFOptions) (*PixelFont, error) {
+	f, err := parseTTFTables(buf)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Height <= 0 || f.ascender-f.descender <= 0 {
+		return nil, errors.New(FontFormatError)
+	}
+	r := ttfRaster{f: f, threshold: opts.Threshold}
+	if r.threshold <= 0 {
+		r.threshold = 0.5
+	}
+	r.scale = float64(opts.Height) / float64(f.ascender-f.descender)
+
+	cmap, err := f.charMap(opts.Ranges)
+	if err != nil {
+		return nil, err
+	}
+	font := &PixelFont{Chars: map[int]PixelChar{}}
+	font.Ascent = int(math.Round(float64(f.ascender) * r.scale))
+	font.Descent = opts.Height - font.Ascent
+	for code, g := range cmap {
+		font.Chars[code] = r.rasterize(g)
+	}
+	font.Prepared = true
+	return font, nil
+}
+
+// parseTTFTables internal, reads the table directory and the font header values
+// ----------------------------------------------------------------------------------------------------------------------
+func parseTTFTables(buf []byte) (*ttfFont, error) {
+	f := &ttfFont{buf: buf, tables: map[string][]byte{}}
+	if len(buf) < 12 {
+		return nil, errors.New(FontFormatError)
+	}
+	start := 0
+	if string(buf[:4]) == "ttcf" {
+		//font collection, the first font is used
+		if len(buf) < 16 {
+			return nil, errors.New(FontFormatError)
+		}
+		start = int(binary.BigEndian.Uint32(buf[12:]))
+	}
+	if start+12 > len(buf) {
+		return nil, errors.New(FontFormatError)
+	}
+	switch string(buf[start : start+4]) {
+	case "\x00\x01\x00\x00", "true", "OTTO":
+	default:
+		return nil, errors.New(FontFormatError)
+	}
+	n := int(binary.BigEndian.Uint16(buf[start+4:]))
+	for i := 0; i < n; i++ {
+		rec := start + 12 + i*16
+		if rec+16 > len(buf) {
+			return nil, errors.New(FontFormatError)
+		}
+		off := int(binary.BigEndian.Uint32(buf[rec+8:]))
+		l := int(binary.BigEndian.Uint32(buf[rec+12:]))
+		if off < 0 || l < 0 || off+l > len(buf) {
+			return nil, errors.New(FontFormatError)
+		}
+		f.tables[string(buf[rec:rec+4])] = buf[off : off+l]
+	}
+	head, maxp, hhea := f.tables["head"], f.tables["maxp"], f.tables["hhea"]
+	if len(head) < 54 || len(maxp) < 6 || len(hhea) < 36 || f.tables["cmap"] == nil || f.tables["hmtx"] == nil {
+		return nil, errors.New(FontFormatError)
+	}
+	f.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	f.longLoca = binary.BigEndian.Uint16(head[50:]) != 0
+	f.numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+	f.ascender = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	f.descender = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	f.numHMetric = int(binary.BigEndian.Uint16(hhea[34:]))
+	//TrueType outlines, or CFF outlines of an OpenType font, CFF2 (variable fonts) is not supported
+	if f.tables["glyf"] != nil && f.tables["loca"] != nil {
+		return f, nil
+	}
+	if f.tables["CFF "] == nil {
+		return nil, errors.New(TTFOutlineError)
+	}
+	cff, err := parseCFF(f.tables["CFF "], f.numGlyphs)
+	if err != nil {
+		return nil, err
+	}
+	f.cff = cff
+	return f, nil
+}
+
+// charMap internal, reads the unicode cmap subtable (format 4 or 12) and returns the glyph index of every
+// code point within the ranges
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *ttfFont) charMap(ranges [][2]int) (map[int]int, error) {
+	cmap := f.tables["cmap"]
+	if len(cmap) < 4 {
+		return nil, errors.New(FontFormatError)
+	}
+	in := func(c int) bool {
+		if ranges == nil {
+			return true
+		}
+		for _, r := range ranges {
+			if c >= r[0] && c <= r[1] {
+				return true
+			}
+		}
+		return false
+	}
+	//preferred subtables: unicode full repertoire before unicode BMP
+	best, bestRank := -1, 0
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n && 4+i*8+8 <= len(cmap); i++ {
+		rec := cmap[4+i*8:]
+		platform, encoding := binary.BigEndian.Uint16(rec), binary.BigEndian.Uint16(rec[2:])
+		off := int(binary.BigEndian.Uint32(rec[4:]))
+		if off+4 > len(cmap) {
+			continue
+		}
+		format := binary.BigEndian.Uint16(cmap[off:])
+		rank := 0
+		switch {
+		case format == 12 && (platform == 0 || (platform == 3 && encoding == 10)):
+			rank = 2
+		case format == 4 && (platform == 0 || (platform == 3 && encoding == 1)):
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = off, rank
+		}
+	}
+	if best < 0 {
+		return nil, errors.New(FontFormatError)
+	}
+
+	m := map[int]int{}
+	t := cmap[best:]
+	if bestRank == 2 {
+		if len(t) < 16 {
+			return nil, errors.New(FontFormatError)
+		}
+		groups := int(binary.BigEndian.Uint32(t[12:]))
+		for i := 0; i < groups && 16+i*12+12 <= len(t); i++ {
+			g := t[16+i*12:]
+			first, last := int(binary.BigEndian.Uint32(g)), int(binary.BigEndian.Uint32(g[4:]))
+			glyph := int(binary.BigEndian.Uint32(g[8:]))
+			for c := first; c <= last && c <= 0x10FFFF; c++ {
+				if in(c) && glyph+c-first < f.numGlyphs {
+					m[c] = glyph + c - first
+				}
+			}
+		}
+		return m, nil
+	}
+	if len(t) < 14 {
+		return nil, errors.New(FontFormatError)
+	}
+	seg := int(binary.BigEndian.Uint16(t[6:])) / 2
+	if 16+seg*8 > len(t) {
+		return nil, errors.New(FontFormatError)
+	}
+	ends, starts := t[14:], t[16+seg*2:]
+	deltas, offsets := t[16+seg*4:], t[16+seg*6:]
+	for i := 0; i < seg; i++ {
+		first, last := int(binary.BigEndian.Uint16(starts[i*2:])), int(binary.BigEndian.Uint16(ends[i*2:]))
+		delta := int(binary.BigEndian.Uint16(deltas[i*2:]))
+		ro := int(binary.BigEndian.Uint16(offsets[i*2:]))
+		for c := first; c <= last && c != 0xFFFF; c++ {
+			if !in(c) {
+				continue
+			}
+			glyph := 0
+			if ro == 0 {
+				glyph = (c + delta) & 0xFFFF
+			} else {
+				//idRangeOffset is relative to its own position in the table
+				pos := 16 + seg*6 + i*2 + ro + (c-first)*2
+				if pos+2 > len(t) {
+					continue
+				}
+				if glyph = int(binary.BigEndian.Uint16(t[pos:])); glyph != 0 {
+					glyph = (glyph + delta) & 0xFFFF
+				}
+			}
+			if glyph > 0 && glyph < f.numGlyphs {
+				m[c] = glyph
+			}
+		}
+	}
+	return m, nil
+}
+
+// advance internal, returns the advance width of a glyph in font units
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *ttfFont) advance(g int) int {
+	hmtx := f.tables["hmtx"]
+	i := minInt(g, f.numHMetric-1) * 4
+	if i < 0 || i+2 > len(hmtx) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(hmtx[i:]))
+}
+
+// glyphData internal, returns the glyf table entry of a glyph
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *ttfFont) glyphData(g int) []byte {
+	loca, glyf := f.tables["loca"], f.tables["glyf"]
+	if g < 0 || g >= f.numGlyphs {
+		return nil
+	}
+	var a, b int
+	if f.longLoca {
+		if g*4+8 > len(loca) {
+			return nil
+		}
+		a, b = int(binary.BigEndian.Uint32(loca[g*4:])), int(binary.BigEndian.Uint32(loca[g*4+4:]))
+	} else {
+		if g*2+4 > len(loca) {
+			return nil
+		}
+		a, b = int(binary.BigEndian.Uint16(loca[g*2:]))*2, int(binary.BigEndian.Uint16(loca[g*2+2:]))*2
+	}
+	if a >= b || b > len(glyf) {
+		return nil
+	}
+	return glyf[a:b]
+}
+
+// contours internal, returns the outline contours of a glyph, composite glyphs are resolved up to a depth of 8
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *ttfFont) contours(g, depth int) [][]ttfPoint {
+	d := f.glyphData(g)
+	if len(d) < 10 || depth > 8 {
+		return nil
+	}
+	n := int(int16(binary.BigEndian.Uint16(d)))
+	if n < 0 {
+		return f.composite(d[10:], depth)
+	}
+	pos := 10
+	if pos+n*2+2 > len(d) {
+		return nil
+	}
+	ends := make([]int, n)
+	for i := range ends {
+		ends[i] = int(binary.BigEndian.Uint16(d[pos+i*2:]))
+	}
+	pos += n * 2
+	pos += 2 + int(binary.BigEndian.Uint16(d[pos:]))
+	if n == 0 {
+		return nil
+	}
+	count := ends[n-1] + 1
+	flags := make([]byte, 0, count)
+	for len(flags) < count && pos < len(d) {
+		fl := d[pos]
+		pos++
+		flags = append(flags, fl)
+		if fl&0x08 != 0 && pos < len(d) {
+			for r := int(d[pos]); r > 0 && len(flags) < count; r-- {
+				flags = append(flags, fl)
+			}
+			pos++
+		}
+	}
+	if len(flags) < count {
+		return nil
+	}
+	pts := make([]ttfPoint, count)
+	//x coordinates, then y coordinates, short values with a sign flag or words
+	for axis := 0; axis < 2; axis++ {
+		short, same := byte(0x02), byte(0x10)
+		if axis == 1 {
+			short, same = 0x04, 0x20
+		}
+		v := 0
+		for i, fl := range flags {
+			switch {
+			case fl&short != 0:
+				if pos >= len(d) {
+					return nil
+				}
+				if fl&same != 0 {
+					v += int(d[pos])
+				} else {
+					v -= int(d[pos])
+				}
+				pos++
+			case fl&same == 0:
+				if pos+2 > len(d) {
+					return nil
+				}
+				v += int(int16(binary.BigEndian.Uint16(d[pos:])))
+				pos += 2
+			}
+			if axis == 0 {
+				pts[i].x = float64(v)
+			} else {
+				pts[i].y = float64(v)
+			}
+			pts[i].onCurve = fl&0x01 != 0
+		}
+	}
+	var cs [][]ttfPoint
+	s := 0
+	for _, e := range ends {
+		if e >= s && e < count {
+			cs = append(cs, pts[s:e+1])
+		}
+		s = e + 1
+	}
+	return cs
+}
+
+// composite internal, resolves the components of a composite glyph, only x,y offsets are supported
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *ttfFont) composite(d []byte, depth int) [][]ttfPoint {
+	var cs [][]ttfPoint
+	for pos := 0; pos+4 <= len(d); {
+		flags := binary.BigEndian.Uint16(d[pos:])
+		g := int(binary.BigEndian.Uint16(d[pos+2:]))
+		pos += 4
+		var dx, dy float64
+		if flags&0x01 != 0 {
+			if pos+4 > len(d) {
+				break
+			}
+			dx, dy = float64(int16(binary.BigEndian.Uint16(d[pos:]))), float64(int16(binary.BigEndian.Uint16(d[pos+2:])))
+			pos += 4
+		} else {
+			if pos+2 > len(d) {
+				break
+			}
+			dx, dy = float64(int8(d[pos])), float64(int8(d[pos+1]))
+			pos += 2
+		}
+		if flags&0x02 == 0 {
+			//point matching is not supported
+			dx, dy = 0, 0
+		}
+		a, b, c, e := 1.0, 0.0, 0.0, 1.0
+		f2 := func(i int) float64 {
+			if pos+i*2+2 > len(d) {
+				return 0
+			}
+			return float64(int16(binary.BigEndian.Uint16(d[pos+i*2:]))) / 16384
+		}
+		switch {
+		case flags&0x08 != 0:
+			a = f2(0)
+			e = a
+			pos += 2
+		case flags&0x40 != 0:
+			a, e = f2(0), f2(1)
+			pos += 4
+		case flags&0x80 != 0:
+			a, b, c, e = f2(0), f2(1), f2(2), f2(3)
+			pos += 8
+		}
+		for _, contour := range f.contours(g, depth+1) {
+			t := make([]ttfPoint, len(contour))
+			for i, pt := range contour {
+				t[i] = ttfPoint{pt.x*a + pt.y*c + dx, pt.x*b + pt.y*e + dy, pt.onCurve}
+			}
+			cs = append(cs, t)
+		}
+		if flags&0x20 == 0 {
+			break
+		}
+	}
+	return cs
+}
+
+// outline internal, returns the contours of a glyph as polygons in pixel coordinates, y down from the baseline
+// ----------------------------------------------------------------------------------------------------------------------
+func (f *ttfFont) outline(g int, scale float64) [][][2]float64 {
+	if f.cff != nil {
+		return f.cff.outline(g, scale)
+	}
+	var polys [][][2]float64
+	for _, c := range f.contours(g, 0) {
+		if poly := ttfFlatten(c, scale); len(poly) > 0 {
+			polys = append(polys, poly)
+		}
+	}
+	return polys
+}
+
+// rasterize internal, converts a glyph into a prepared char. The bitmap covers just the outline, the char
+// stands on the baseline moved by OffsetX (the left side bearing) and OffsetY
+// ----------------------------------------------------------------------------------------------------------------------
+func (o *ttfRaster) rasterize(g int) PixelChar {
+	f := o.f
+	ch := PixelChar{Advance: int(math.Round(float64(f.advance(g)) * o.scale))}
+	var polys [][][2]float64
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, poly := range f.outline(g, o.scale) {
+		for _, pt := range poly {
+			minX, maxX = math.Min(minX, pt[0]), math.Max(maxX, pt[0])
+			minY, maxY = math.Min(minY, pt[1]), math.Max(maxY, pt[1])
+		}
+		polys = append(polys, poly)
+	}
+	if len(polys) == 0 {
+		return ch
+	}
+	left, top := int(math.Floor(minX)), int(math.Floor(minY))
+	ch.OffsetX = left
+	ch.SizeX = minInt(int(math.Ceil(maxX))-left, 64)
+	ch.SizeY = int(math.Ceil(maxY)) - top
+	ch.OffsetY = top + ch.SizeY
+	rows := make([]uint64, ch.SizeY)
+	if ch.SizeX > 0 {
+		cover := ttfCoverage(polys, left, top, ch.SizeX, ch.SizeY)
+		limit := int(math.Ceil(o.threshold * ttfSamples * ttfSamples))
+		for y := range rows {
+			for x := 0; x < ch.SizeX; x++ {
+				if cover[y*ch.SizeX+x] >= limit {
+					rows[y] |= 1 << uint(ch.SizeX-1-x)
+				}
+			}
+		}
+	}
+	ch.Data, ch.Len = leftBound(rows, maxInt(ch.SizeX, 1))
+	return ch
+}
+
+// ttfFlatten internal, converts a quadratic contour into a polygon in pixel coordinates, y down from the baseline
+// ----------------------------------------------------------------------------------------------------------------------
+func ttfFlatten(c []ttfPoint, scale float64) [][2]float64 {
+	n := len(c)
+	if n == 0 {
+		return nil
+	}
+	px := func(p ttfPoint) [2]float64 {
+		return [2]float64{p.x * scale, -p.y * scale}
+	}
+	mid := func(a, b ttfPoint) ttfPoint {
+		return ttfPoint{(a.x + b.x) / 2, (a.y + b.y) / 2, true}
+	}
+	//start at an on curve point, a contour without one starts between the first two points
+	first := -1
+	for i, p := range c {
+		if p.onCurve {
+			first = i
+			break
+		}
+	}
+	var start ttfPoint
+	if first < 0 {
+		start = mid(c[0], c[1%n])
+		first = 0
+	} else {
+		start = c[first]
+		first++
+	}
+	poly := [][2]float64{px(start)}
+	cur := start
+	var ctrl *ttfPoint
+	for k := 0; k < n; k++ {
+		p := c[(first+k)%n]
+		if p.onCurve {
+			if ctrl != nil {
+				poly = append(poly, ttfQuad(cur, *ctrl, p, scale)...)
+				ctrl = nil
+			} else {
+				poly = append(poly, px(p))
+			}
+			cur = p
+			continue
+		}
+		if ctrl != nil {
+			m := mid(*ctrl, p)
+			poly = append(poly, ttfQuad(cur, *ctrl, m, scale)...)
+			cur = m
+		}
+		q := p
+		ctrl = &q
+	}
+	if ctrl != nil {
+		poly = append(poly, ttfQuad(cur, *ctrl, start, scale)...)
+	}
+	return poly
+}
+
+// ttfQuad internal, flattens a quadratic bezier from a to c with control point b, a itself is not returned
+// ----------------------------------------------------------------------------------------------------------------------
+func ttfQuad(a, b, c ttfPoint, scale float64) [][2]float64 {
+	steps := 8
+	pts := make([][2]float64, 0, steps)
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		x := (1-t)*(1-t)*a.x + 2*(1-t)*t*b.x + t*t*c.x
+		y := (1-t)*(1-t)*a.y + 2*(1-t)*t*b.y + t*t*c.y
+		pts = append(pts, [2]float64{x * scale, -y * scale})
+	}
+	return pts
+}
+
+// ttfCoverage internal, returns the number of covered samples for each pixel of a w,h box starting at
+// column left and row top, filled by the non-zero winding rule
+// ----------------------------------------------------------------------------------------------------------------------
+func ttfCoverage(polys [][][2]float64, left, top, w, h int) []int {
+	type crossing struct {
+		x   float64
+		dir int
+	}
+	cover := make([]int, w*h)
+	var xs []crossing
+	for sy := 0; sy < h*ttfSamples; sy++ {
+		y := float64(top) + (float64(sy)+0.5)/ttfSamples
+		xs = xs[:0]
+		for _, poly := range polys {
+			for i := range poly {
+				a, b := poly[i], poly[(i+1)%len(poly)]
+				dir := 1
+				if a[1] > b[1] {
+					a, b = b, a
+					dir = -1
+				}
+				if y < a[1] || y >= b[1] {
+					continue
+				}
+				xs = append(xs, crossing{a[0] + (y-a[1])*(b[0]-a[0])/(b[1]-a[1]), dir})
+			}
+		}
+		sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+		wind := 0
+		for i := 0; i+1 < len(xs); i++ {
+			wind += xs[i].dir
+			if wind == 0 {
+				continue
+			}
+			//samples with their center inside the span
+			s1 := int(math.Ceil((xs[i].x-float64(left))*ttfSamples - 0.5))
+			s2 := int(math.Ceil((xs[i+1].x-float64(left))*ttfSamples - 0.5))
+			for sx := maxInt(s1, 0); sx < minInt(s2, w*ttfSamples); sx++ {
+				cover[(sy/ttfSamples)*w+sx/ttfSamples]++
+			}
+		}
+	}
+	return cover
+}
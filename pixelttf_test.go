@@ -0,0 +1,267 @@
+package pixelding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"testing"
+)
+
+// testGlyph internal test helper, a glyph of a generated font with its outline table entry
+type testGlyph struct {
+	code    rune
+	advance int
+	data    []byte
+}
+
+// testFontFile internal test helper, builds a font file with 1000 units per em, ascender 800 and descender
+// -200. The outline tables are the glyph data, glyf and loca for "glyf", the given CFF table for "CFF "
+func testFontFile(outlines string, glyphs []testGlyph, cff []byte) []byte {
+	be := binary.BigEndian
+	w := func(b *bytes.Buffer, v ...interface{}) {
+		for _, x := range v {
+			_ = binary.Write(b, be, x)
+		}
+	}
+	n := len(glyphs) + 1 //glyph 0 is .notdef
+	tables := map[string][]byte{}
+
+	var head bytes.Buffer
+	w(&head, uint32(0x00010000), uint32(0), uint32(0), uint32(0x5F0F3CF5), uint16(0), uint16(1000))
+	head.Write(make([]byte, 16+8+6))
+	w(&head, int16(1), int16(0)) //long loca
+	tables["head"] = head.Bytes()
+
+	var hhea bytes.Buffer
+	w(&hhea, uint32(0x00010000), int16(800), int16(-200))
+	hhea.Write(make([]byte, 26))
+	w(&hhea, uint16(n))
+	tables["hhea"] = hhea.Bytes()
+
+	var maxp bytes.Buffer
+	w(&maxp, uint32(0x00005000), uint16(n))
+	tables["maxp"] = maxp.Bytes()
+
+	var hmtx bytes.Buffer
+	w(&hmtx, uint16(500), int16(0))
+	for _, g := range glyphs {
+		w(&hmtx, uint16(g.advance), int16(0))
+	}
+	tables["hmtx"] = hmtx.Bytes()
+
+	//cmap format 4, one segment per char
+	order := make([]int, len(glyphs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return glyphs[order[i]].code < glyphs[order[j]].code })
+	seg := len(glyphs) + 1
+	var sub bytes.Buffer
+	w(&sub, uint16(4), uint16(16+seg*8), uint16(0), uint16(seg*2), uint16(0), uint16(0), uint16(0))
+	for _, i := range order {
+		w(&sub, uint16(glyphs[i].code))
+	}
+	w(&sub, uint16(0xFFFF), uint16(0))
+	for _, i := range order {
+		w(&sub, uint16(glyphs[i].code))
+	}
+	w(&sub, uint16(0xFFFF))
+	for _, i := range order {
+		w(&sub, uint16(i+1-int(glyphs[i].code)))
+	}
+	w(&sub, uint16(1))
+	sub.Write(make([]byte, seg*2))
+	var cmap bytes.Buffer
+	w(&cmap, uint16(0), uint16(1), uint16(3), uint16(1), uint32(12))
+	cmap.Write(sub.Bytes())
+	tables["cmap"] = cmap.Bytes()
+
+	tag := "OTTO"
+	if outlines == "glyf" {
+		tag = "\x00\x01\x00\x00"
+		var glyf, loca bytes.Buffer
+		w(&loca, uint32(0))
+		w(&loca, uint32(0))
+		for _, g := range glyphs {
+			glyf.Write(g.data)
+			w(&loca, uint32(glyf.Len()))
+		}
+		tables["glyf"], tables["loca"] = glyf.Bytes(), loca.Bytes()
+	} else {
+		tables["CFF "] = cff
+	}
+
+	tags := make([]string, 0, len(tables))
+	for t := range tables {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	var out bytes.Buffer
+	out.WriteString(tag)
+	w(&out, uint16(len(tags)), uint16(0), uint16(0), uint16(0))
+	off := 12 + 16*len(tags)
+	for _, t := range tags {
+		out.WriteString(t)
+		w(&out, uint32(0), uint32(off), uint32(len(tables[t])))
+		off += (len(tables[t]) + 3) &^ 3
+	}
+	for _, t := range tags {
+		out.Write(tables[t])
+		out.Write(make([]byte, (4-len(tables[t])%4)%4))
+	}
+	return out.Bytes()
+}
+
+// glyfRect internal test helper, a simple glyf glyph of one rectangle x0,y0 to x1,y1 in font units
+func glyfRect(x0, y0, x1, y1 int16) []byte {
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.BigEndian, []int16{1, x0, y0, x1, y1, 3, 0})
+	b.Write([]byte{1, 1, 1, 1}) //on curve, word coordinates
+	_ = binary.Write(&b, binary.BigEndian, []int16{x0, 0, x1 - x0, 0, y0, y1 - y0, 0, y0 - y1})
+	return b.Bytes()
+}
+
+func TestParseTTFMetrics(t *testing.T) {
+	buf := testFontFile("glyf", []testGlyph{
+		{'I', 600, glyfRect(200, 0, 500, 700)}, //left side bearing 2 pixels
+		{'_', 400, glyfRect(-100, -200, 300, -100)},
+		{' ', 300, nil},
+	}, nil)
+	font, err := ParseTTF(buf, TTFOptions{Height: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if font.Ascent != 8 || font.Descent != 2 {
+		t.Errorf("ascent %d descent %d, want 8 2", font.Ascent, font.Descent)
+	}
+	tests := []struct {
+		code                    int
+		offX, offY, sizeX, rows int
+		advance                 int
+	}{
+		{'I', 2, 0, 3, 7, 6},
+		{'_', -1, 2, 4, 1, 4},
+		{' ', 0, 0, 0, 0, 3},
+	}
+	for _, tt := range tests {
+		ch, ok := font.Chars[tt.code]
+		if !ok {
+			t.Fatalf("char %q missing", rune(tt.code))
+		}
+		if ch.OffsetX != tt.offX || ch.OffsetY != tt.offY || ch.SizeX != tt.sizeX || len(ch.Data) != tt.rows ||
+			ch.Advance != tt.advance {
+			t.Errorf("char %c: offset %d,%d size %d rows %d advance %d, want %d,%d %d %d %d", tt.code, ch.OffsetX,
+				ch.OffsetY, ch.SizeX, len(ch.Data), ch.Advance, tt.offX, tt.offY, tt.sizeX, tt.rows, tt.advance)
+		}
+		for y, row := range ch.Data {
+			if want := uint64(1<<tt.sizeX-1) << uint(64-tt.sizeX); row != want {
+				t.Errorf("char %c row %d is %064b", tt.code, y, row)
+			}
+		}
+	}
+
+	p := New(20, 12)
+	p.FontPrint(font, 0, 0, "I_", true)
+	got := pixels(&p)
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 20; x++ {
+			want := x >= 2 && x < 5 && y >= 1 && y < 8 || //I
+				x >= 5 && x < 9 && y == 9 //_ at the pen position 6, moved left by one
+			if got[[2]int{x, y}] != want {
+				t.Errorf("pixel %d,%d set %v, want %v", x, y, got[[2]int{x, y}], want)
+			}
+		}
+	}
+}
+
+// t2 internal test helper, encodes a Type 2 charstring, ints are operands, byte slices are operators
+func t2(code ...interface{}) []byte {
+	var b []byte
+	for _, c := range code {
+		switch v := c.(type) {
+		case int:
+			b = append(b, 28, byte(uint16(v)>>8), byte(v))
+		case []byte:
+			b = append(b, v...)
+		}
+	}
+	return b
+}
+
+// testCFFIndex internal test helper, an INDEX with one byte offsets
+func testCFFIndex(items ...[]byte) []byte {
+	if len(items) == 0 {
+		return []byte{0, 0}
+	}
+	b := []byte{0, byte(len(items)), 1, 1}
+	var data []byte
+	for _, it := range items {
+		data = append(data, it...)
+		b = append(b, byte(len(data)+1))
+	}
+	return append(b, data...)
+}
+
+// testCFF internal test helper, a CFF table with the charstrings (glyph 0 is .notdef), global and local subrs
+func testCFF(charStrings [][]byte, gsubrs, subrs [][]byte) []byte {
+	i32 := func(v int) []byte { return []byte{29, byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)} }
+	glyphs := testCFFIndex(append([][]byte{t2([]byte{14})}, charStrings...)...)
+	private := append(i32(6), 19) //the local subrs follow the private dict
+	head := []byte{1, 0, 4, 1}
+	head = append(head, testCFFIndex([]byte("T"))...)
+	topLen := 5 + 17 //top dict INDEX header, three operands with two operators
+	start := len(head) + topLen + 2 + len(testCFFIndex(gsubrs...))
+	top := append(i32(start), 17)
+	top = append(append(append(top, i32(len(private))...), i32(start+len(glyphs))...), 18)
+	out := append(head, testCFFIndex(top)...)
+	out = append(append(out, 0, 0), testCFFIndex(gsubrs...)...) //no strings
+	out = append(append(out, glyphs...), private...)
+	return append(out, testCFFIndex(subrs...)...)
+}
+
+func TestParseTTFCFF(t *testing.T) {
+	var (
+		hstemhm   = []byte{18}
+		hintmask  = []byte{19, 0xC0} //two stems, one mask byte
+		rmoveto   = []byte{21}
+		hlineto   = []byte{6}
+		vlineto   = []byte{7}
+		callsubr  = []byte{10}
+		callgsubr = []byte{29}
+		ret       = []byte{11}
+		endchar   = []byte{14}
+		hflex     = []byte{12, 34}
+	)
+	cff := testCFF([][]byte{
+		//width, stems, a hintmask with an implicit vstem, the rectangle 200,0 500,700 partly in subroutines
+		t2(100, 0, 700, hstemhm, 200, 300, hintmask, 200, 0, rmoveto, -107, callsubr, -107, callgsubr, endchar),
+		//a hump from 200 to 500 up to 300
+		t2(200, 0, rmoveto, 50, 50, 300, 50, 50, 50, 50, hflex, endchar),
+	}, [][]byte{t2(700, vlineto, -300, hlineto, ret)}, [][]byte{t2(300, hlineto, ret)})
+	glyphs := []testGlyph{{'I', 600, nil}, {'n', 600, nil}}
+	font, err := ParseTTF(testFontFile("CFF ", glyphs, cff), TTFOptions{Height: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := font.Chars['I']
+	if ch.OffsetX != 2 || ch.OffsetY != 0 || ch.SizeX != 3 || len(ch.Data) != 7 || ch.Advance != 6 {
+		t.Errorf("char I: offset %d,%d size %d rows %d advance %d", ch.OffsetX, ch.OffsetY, ch.SizeX, len(ch.Data),
+			ch.Advance)
+	}
+	for y, row := range ch.Data {
+		if row != 7<<61 {
+			t.Errorf("char I row %d is %064b", y, row)
+		}
+	}
+	ch = font.Chars['n']
+	if ch.OffsetX != 2 || ch.OffsetY != 0 || ch.SizeX != 3 || len(ch.Data) != 3 || ch.Data[2] != 7<<61 {
+		t.Errorf("char n: offset %d,%d size %d data %064b", ch.OffsetX, ch.OffsetY, ch.SizeX, ch.Data)
+	}
+
+	//CFF2 outlines are not supported
+	buf := testFontFile("CFF ", glyphs, cff)
+	copy(buf[bytes.Index(buf, []byte("CFF ")):], "CFF2")
+	if _, err := ParseTTF(buf, TTFOptions{Height: 10}); err == nil || err.Error() != TTFOutlineError {
+		t.Errorf("CFF2 font: err %v, want %s", err, TTFOutlineError)
+	}
+}